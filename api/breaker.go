@@ -0,0 +1,247 @@
+package api
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by DoWithConfig without sending the request while the CircuitBreaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through to decide whether to close or reopen the circuit
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerSettings contains settings for creating a CircuitBreaker.
+//
+// ConsecutiveFailures is the number of consecutive failed requests which open the circuit, if neither this or ErrorRate is provided 5 is used.
+//
+// ErrorRate is the ratio of failed requests (between 0 and 1) within a Window which opens the circuit, once at least MinRequests have been made.
+//
+// Window is the period requests are counted over for the ErrorRate, if not provided 1 minute is used.
+//
+// OpenPeriod is how long the circuit stays open before a probe request is let through, if not provided 30 seconds is used.
+//
+// PerCallee is an optional flag to keep a separate circuit for each calleeID, otherwise one circuit is shared by all requests.
+//
+// OnStateChange is an optional hook called whenever a circuit changes state.
+type CircuitBreakerSettings struct {
+	ConsecutiveFailures uint
+	ErrorRate           float64
+	MinRequests         uint
+	Window              time.Duration
+	OpenPeriod          time.Duration
+	PerCallee           bool
+	OnStateChange       func(calleeID string, from CircuitState, to CircuitState)
+}
+
+// CircuitBreaker fails API requests fast while the API is failing, instead of waiting for each request to time out
+//
+// Requests fail when they return a transport error or a 5xx response
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	changes  []stateChange
+}
+
+type stateChange struct {
+	calleeID string
+	from     CircuitState
+	to       CircuitState
+}
+
+type circuit struct {
+	state       CircuitState
+	openedAt    time.Time
+	opened      uint
+	probing     bool
+	consecutive uint
+	windowStart time.Time
+	requests    uint
+	failures    uint
+}
+
+// NewCircuitBreaker instantiates a CircuitBreaker with the specified settings
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.ConsecutiveFailures == 0 && settings.ErrorRate <= 0 {
+		settings.ConsecutiveFailures = 5
+	}
+	if settings.Window <= 0 {
+		settings.Window = time.Minute
+	}
+	if settings.OpenPeriod <= 0 {
+		settings.OpenPeriod = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		settings: settings,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// State returns the current state of the circuit used for the specified calleeID
+func (cb *CircuitBreaker) State(calleeID string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(calleeID)
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= cb.settings.OpenPeriod {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// CircuitToken is returned by Allow for each request let through, the outcome of the request is reported by passing it to Done
+type CircuitToken struct {
+	calleeID string
+	// probe is set for the single request let through by a half-open circuit
+	probe bool
+	// opened is the number of times the circuit had opened when the request was allowed
+	opened uint
+}
+
+// Allow reports whether a request for the specified calleeID may be sent, returning ErrCircuitOpen if not.
+// When allowed the outcome of the request must be reported by passing the returned CircuitToken to Done.
+func (cb *CircuitBreaker) Allow(calleeID string) (CircuitToken, error) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	key := cb.key(calleeID)
+	c := cb.circuit(calleeID)
+	token := CircuitToken{calleeID: calleeID, opened: c.opened}
+	switch c.state {
+	case CircuitOpen:
+		if time.Since(c.openedAt) < cb.settings.OpenPeriod {
+			return CircuitToken{}, ErrCircuitOpen
+		}
+		cb.transition(key, c, CircuitHalfOpen)
+		c.probing = true
+		token.probe = true
+		return token, nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return CircuitToken{}, ErrCircuitOpen
+		}
+		c.probing = true
+		token.probe = true
+		return token, nil
+	}
+	return token, nil
+}
+
+// Done reports the outcome of a request previously allowed, with the CircuitToken returned by Allow
+//
+// Only the outcome of the probe request decides whether a half-open circuit closes or reopens, the outcomes of requests allowed before the
+// circuit last opened are ignored.
+func (cb *CircuitBreaker) Done(token CircuitToken, success bool) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	key := cb.key(token.calleeID)
+	c := cb.circuit(token.calleeID)
+	now := time.Now()
+
+	if token.probe {
+		if c.state != CircuitHalfOpen || !c.probing {
+			return
+		}
+		c.probing = false
+		if success {
+			cb.transition(key, c, CircuitClosed)
+		} else {
+			cb.open(key, c, now)
+		}
+		return
+	}
+	if c.state != CircuitClosed || token.opened != c.opened {
+		// a request allowed before the circuit opened
+		return
+	}
+
+	if now.Sub(c.windowStart) >= cb.settings.Window {
+		c.windowStart = now
+		c.requests = 0
+		c.failures = 0
+	}
+	c.requests++
+	if success {
+		c.consecutive = 0
+		return
+	}
+	c.failures++
+	c.consecutive++
+
+	trip := cb.settings.ConsecutiveFailures > 0 && c.consecutive >= cb.settings.ConsecutiveFailures
+	if !trip && cb.settings.ErrorRate > 0 && c.requests >= cb.settings.MinRequests {
+		trip = float64(c.failures)/float64(c.requests) >= cb.settings.ErrorRate
+	}
+	if trip {
+		cb.open(key, c, now)
+	}
+}
+
+func (cb *CircuitBreaker) open(key string, c *circuit, now time.Time) {
+	c.openedAt = now
+	c.opened++
+	cb.transition(key, c, CircuitOpen)
+}
+
+func (cb *CircuitBreaker) key(calleeID string) string {
+	if cb.settings.PerCallee {
+		return calleeID
+	}
+	return ""
+}
+
+func (cb *CircuitBreaker) circuit(calleeID string) *circuit {
+	key := cb.key(calleeID)
+	c, exists := cb.circuits[key]
+	if !exists {
+		c = &circuit{windowStart: time.Now()}
+		cb.circuits[key] = c
+	}
+	return c
+}
+
+func (cb *CircuitBreaker) transition(key string, c *circuit, to CircuitState) {
+	from := c.state
+	c.state = to
+	if to == CircuitClosed {
+		c.consecutive = 0
+		c.requests = 0
+		c.failures = 0
+		c.windowStart = time.Now()
+	}
+	if from != to && cb.settings.OnStateChange != nil {
+		cb.changes = append(cb.changes, stateChange{key, from, to})
+	}
+}
+
+// unlock releases the lock then calls the OnStateChange hook for any state changes, so the hook can safely use the CircuitBreaker
+func (cb *CircuitBreaker) unlock() {
+	changes := cb.changes
+	cb.changes = nil
+	cb.mu.Unlock()
+	for _, c := range changes {
+		cb.settings.OnStateChange(c.calleeID, c.from, c.to)
+	}
+}
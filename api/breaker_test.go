@@ -0,0 +1,153 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusInternalServerError
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	var changes []string
+	cb := NewCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 3,
+		OpenPeriod:          50 * time.Millisecond,
+		PerCallee:           true,
+		OnStateChange: func(calleeID string, from CircuitState, to CircuitState) {
+			changes = append(changes, calleeID+":"+from.String()+">"+to.String())
+		},
+	})
+	config := Config{Breaker: cb}
+	do := func(calleeID string) error {
+		req, err := BuildRequest("test", "application/json", "GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = DoWithConfig(http.DefaultClient, config, calleeID, req, "")
+		return err
+	}
+
+	// Failures open the circuit
+	for i := 0; i < 3; i++ {
+		if err := do("Event"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cb.State("Event") != CircuitOpen {
+		t.Fatalf("expected circuit to be open but was %s", cb.State("Event"))
+	}
+	// Open circuits fail fast without sending requests
+	if err := do("Event"); err != ErrCircuitOpen {
+		t.Errorf("expected ErrCircuitOpen but returned %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests to be sent but sent %d", requests)
+	}
+	// Other callees have their own circuit
+	if cb.State("Campaign") != CircuitClosed {
+		t.Errorf("expected Campaign circuit to be closed but was %s", cb.State("Campaign"))
+	}
+
+	// A failed probe reopens the circuit
+	time.Sleep(60 * time.Millisecond)
+	if err := do("Event"); err != nil {
+		t.Fatal(err)
+	}
+	if cb.State("Event") != CircuitOpen {
+		t.Fatalf("expected circuit to reopen but was %s", cb.State("Event"))
+	}
+
+	// A successful probe closes the circuit
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	time.Sleep(60 * time.Millisecond)
+	if err := do("Event"); err != nil {
+		t.Fatal(err)
+	}
+	if cb.State("Event") != CircuitClosed {
+		t.Fatalf("expected circuit to close but was %s", cb.State("Event"))
+	}
+
+	expected := []string{"Event:closed>open", "Event:open>half-open", "Event:half-open>open", "Event:open>half-open", "Event:half-open>closed"}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected state changes %v but were %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("expected state changes %v but were %v", expected, changes)
+			break
+		}
+	}
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerSettings{ErrorRate: 0.5, MinRequests: 4})
+	for _, success := range []bool{true, false, true} {
+		token, err := cb.Allow("Event")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb.Done(token, success)
+	}
+	if cb.State("Event") != CircuitClosed {
+		t.Fatalf("expected circuit to stay closed below MinRequests but was %s", cb.State("Event"))
+	}
+	token, _ := cb.Allow("Event")
+	cb.Done(token, false)
+	// 2 failures in 4 requests, and circuits are shared by all callees by default
+	if cb.State("Campaign") != CircuitOpen {
+		t.Errorf("expected circuit to be open but was %s", cb.State("Campaign"))
+	}
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerSettings{ConsecutiveFailures: 1, OpenPeriod: 10 * time.Millisecond})
+	before, err := cb.Allow("Event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, err := cb.Allow("Event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb.Done(failed, false)
+	if cb.State("Event") != CircuitOpen {
+		t.Fatalf("expected circuit to be open but was %s", cb.State("Event"))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	probe, err := cb.Allow("Event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cb.Allow("Event"); err != ErrCircuitOpen {
+		t.Errorf("expected a single probe but returned %v", err)
+	}
+	// a request allowed before the circuit opened does not decide the probe
+	cb.Done(before, true)
+	if cb.State("Event") != CircuitHalfOpen {
+		t.Fatalf("expected circuit to stay half-open until the probe is done but was %s", cb.State("Event"))
+	}
+	cb.Done(probe, true)
+	if cb.State("Event") != CircuitClosed {
+		t.Fatalf("expected the probe to close the circuit but was %s", cb.State("Event"))
+	}
+	// nor is it counted once the circuit closes again
+	cb.Done(failed, false)
+	if cb.State("Event") != CircuitClosed {
+		t.Errorf("expected a stale failure to be ignored but was %s", cb.State("Event"))
+	}
+}
@@ -0,0 +1,362 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheEntry is a response stored by a Cache
+type CacheEntry struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Stored     time.Time   `json:"stored"`
+}
+
+// CacheStorage provides an interface for storing the responses of a Cache, keyed by URL
+//
+// Entry returns nil if there is no entry for the key
+type CacheStorage interface {
+	Entry(key string) (*CacheEntry, error)
+	SetEntry(key string, entry *CacheEntry) error
+}
+
+// CacheStats contains the hit and miss statistics of a Cache.
+//
+// Hits counts responses served from the Cache, either because they were still fresh or after JustGiving confirmed they had not changed (Revalidated).
+//
+// Misses counts the other GET requests, which were fetched in full.
+type CacheStats struct {
+	Hits        uint64
+	Revalidated uint64
+	Misses      uint64
+}
+
+// Cache is an HTTP cache for GET requests, see Transport
+//
+// Responses are stored keyed by URL, fresh responses (see Cache-Control max-age) are served without a request
+// and stale responses are revalidated with If-None-Match/If-Modified-Since, serving a 304 Not Modified from the Cache.
+// Requests with an Authorization header and responses with Cache-Control no-store are never cached.
+type Cache struct {
+	storage CacheStorage
+
+	hits        uint64
+	revalidated uint64
+	misses      uint64
+}
+
+// NewCache instantiates a Cache using the specified storage, if not provided an in memory LRU of 1000 responses is used
+func NewCache(storage CacheStorage) *Cache {
+	if storage == nil {
+		storage = NewMemoryCacheStorage(1000)
+	}
+	return &Cache{storage: storage}
+}
+
+// Stats returns the hit and miss statistics of the Cache
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:        atomic.LoadUint64(&c.hits),
+		Revalidated: atomic.LoadUint64(&c.revalidated),
+		Misses:      atomic.LoadUint64(&c.misses),
+	}
+}
+
+// Transport returns an http.RoundTripper which caches the responses of base (or http.DefaultTransport if nil)
+//
+// Any redact strings (e.g. the API key) are replaced in the URL used as the cache key.
+func (c *Cache) Transport(base http.RoundTripper, redact ...string) http.RoundTripper {
+	return c.TransportWithLimit(base, 0, redact...)
+}
+
+// TransportWithLimit returns an http.RoundTripper as Transport, which only reads up to maxBodyBytes of a response into memory (0 is unlimited)
+//
+// Larger responses are not stored and are returned unread beyond the limit, so the MaxResponseBytes of the Config rejects them as usual.
+func (c *Cache) TransportWithLimit(base http.RoundTripper, maxBodyBytes int64, redact ...string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cacheTransport{cache: c, base: base, redact: redact, maxBodyBytes: maxBodyBytes}
+}
+
+type cacheTransport struct {
+	cache        *Cache
+	base         http.RoundTripper
+	redact       []string
+	maxBodyBytes int64
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	key := redact(req.URL.String(), t.redact)
+	entry, err := t.cache.storage.Entry(key)
+	if err != nil {
+		// treat an unreadable entry as a miss
+		entry = nil
+	}
+	reqDirectives := cacheControl(req.Header)
+	if entry != nil && !reqDirectives.has("no-cache") && entry.fresh(time.Now()) {
+		atomic.AddUint64(&t.cache.hits, 1)
+		return entry.response(req), nil
+	}
+
+	if entry != nil {
+		etag, lastModified := entry.Header.Get("ETag"), entry.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			// clone the request rather than modify the caller's
+			req = req.Clone(req.Context())
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				req.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		res.Body.Close()
+		atomic.AddUint64(&t.cache.hits, 1)
+		atomic.AddUint64(&t.cache.revalidated, 1)
+		// refresh the stored headers, e.g. Cache-Control and Date
+		for k, v := range res.Header {
+			entry.Header[k] = v
+		}
+		entry.Stored = time.Now()
+		t.cache.storage.SetEntry(key, entry)
+		return entry.response(req), nil
+	}
+
+	atomic.AddUint64(&t.cache.misses, 1)
+	if res.StatusCode != http.StatusOK || !cacheable(res.Header) {
+		return res, nil
+	}
+	var r io.Reader = res.Body
+	if t.maxBodyBytes > 0 {
+		// read one byte more than the limit to tell whether the body is too large
+		r = io.LimitReader(res.Body, t.maxBodyBytes+1)
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if t.maxBodyBytes > 0 && int64(len(body)) > t.maxBodyBytes {
+		// too large to store, return the rest of the body unread
+		res.Body = readCloser{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		return res, nil
+	}
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.cache.storage.SetEntry(key, &CacheEntry{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       body,
+		Stored:     time.Now(),
+	})
+	return res, nil
+}
+
+// readCloser reads from a Reader, closing a Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// fresh reports whether the entry can be served without revalidating it
+func (e *CacheEntry) fresh(now time.Time) bool {
+	directives := cacheControl(e.Header)
+	if directives.has("no-cache") {
+		return false
+	}
+	maxAge, ok := directives["max-age"]
+	if !ok {
+		return false
+	}
+	seconds, err := strconv.ParseInt(maxAge, 10, 64)
+	if err != nil {
+		return false
+	}
+	return now.Before(e.Stored.Add(time.Duration(seconds) * time.Second))
+}
+
+func (e *CacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable reports whether a response can be stored, i.e. it is not no-store and can be revalidated or has a max-age
+func cacheable(h http.Header) bool {
+	directives := cacheControl(h)
+	if directives.has("no-store") {
+		return false
+	}
+	_, maxAge := directives["max-age"]
+	return maxAge || h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+type directives map[string]string
+
+func (d directives) has(directive string) bool {
+	_, ok := d[directive]
+	return ok
+}
+
+// cacheControl parses the Cache-Control header directives
+func cacheControl(h http.Header) directives {
+	result := make(directives)
+	for _, v := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value := part, ""
+			if i := strings.Index(part, "="); i >= 0 {
+				name, value = part[:i], strings.Trim(part[i+1:], `"`)
+			}
+			result[strings.ToLower(name)] = value
+		}
+	}
+	return result
+}
+
+// NewMemoryCacheStorage returns a CacheStorage which keeps up to maxEntries responses in memory, discarding the least recently used
+func NewMemoryCacheStorage(maxEntries int) CacheStorage {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &memoryCacheStorage{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+type memoryCacheStorage struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+func (s *memoryCacheStorage) Entry(key string) (*CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, exists := s.entries[key]
+	if !exists {
+		return nil, nil
+	}
+	s.lru.MoveToFront(e)
+	// return a copy so callers can't change the stored entry
+	entry := e.Value.(*memoryCacheItem).entry
+	entry.Header = entry.Header.Clone()
+	return &entry, nil
+}
+
+func (s *memoryCacheStorage) SetEntry(key string, entry *CacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := &memoryCacheItem{key: key, entry: *entry}
+	item.entry.Header = entry.Header.Clone()
+	if e, exists := s.entries[key]; exists {
+		e.Value = item
+		s.lru.MoveToFront(e)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(item)
+	for s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// NewFileCacheStorage returns a CacheStorage which keeps responses as files in the specified directory, creating it if required
+func NewFileCacheStorage(dir string) (CacheStorage, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &fileCacheStorage{dir: dir}, nil
+}
+
+type fileCacheStorage struct {
+	dir string
+}
+
+func (s *fileCacheStorage) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *fileCacheStorage) Entry(key string) (*CacheEntry, error) {
+	b, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry CacheEntry
+	if err = json.Unmarshal(b, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *fileCacheStorage) SetEntry(key string, entry *CacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file and rename it so readers never see a partial entry
+	tmp, err := ioutil.TempFile(s.dir, "entry")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
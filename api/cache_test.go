@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/secretkey/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/secretkey/etag":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/secretkey/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("ETag", `"v1"`)
+		}
+		w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "justin-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileStorage, err := NewFileCacheStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, storage := range map[string]CacheStorage{"memory": NewMemoryCacheStorage(10), "file": fileStorage} {
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&notModified, 0)
+		cache := NewCache(storage)
+		client := &http.Client{Transport: cache.Transport(nil, "secretkey")}
+		get := func(path string) string {
+			res, body, err := DoWithConfig(client, Config{}, "Test", mustRequest(t, server.URL+"/secretkey"+path), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != 200 {
+				t.Fatalf("%s: expected 200, got %s", name, res.Status)
+			}
+			return body
+		}
+
+		for i := 0; i < 3; i++ {
+			if body := get("/fresh"); body != `{"path":"/secretkey/fresh"}` {
+				t.Errorf("%s: unexpected body %s", name, body)
+			}
+			if body := get("/etag"); body != `{"path":"/secretkey/etag"}` {
+				t.Errorf("%s: unexpected body %s", name, body)
+			}
+			get("/nostore")
+		}
+		// fresh is fetched once, etag once in full and twice revalidated, nostore every time
+		if requests != 7 || notModified != 2 {
+			t.Errorf("%s: expected 7 requests with 2 not modified, got %d and %d", name, requests, notModified)
+		}
+		stats := cache.Stats()
+		if stats.Hits != 4 || stats.Revalidated != 2 || stats.Misses != 5 {
+			t.Errorf("%s: unexpected stats %+v", name, stats)
+		}
+		if entry, _ := storage.Entry(server.URL + "/REDACTED/fresh"); entry == nil {
+			t.Errorf("%s: expected entry keyed by redacted url", name)
+		}
+	}
+}
+
+func TestCacheTransportWithLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	storage := NewMemoryCacheStorage(10)
+	client := &http.Client{Transport: NewCache(storage).TransportWithLimit(nil, 20)}
+	config := Config{MaxResponseBytes: 20}
+
+	// {"path":"/small"} is 17 bytes, {"path":"/too/large"} is 21
+	if _, body, err := DoWithConfig(client, config, "Test", mustRequest(t, server.URL+"/small"), ""); err != nil || body != `{"path":"/small"}` {
+		t.Errorf("expected small response, got %s %v", body, err)
+	}
+	if _, _, err := DoWithConfig(client, config, "Test", mustRequest(t, server.URL+"/too/large"), ""); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if entry, _ := storage.Entry(server.URL + "/small"); entry == nil {
+		t.Error("expected the small response to be stored")
+	}
+	if entry, _ := storage.Entry(server.URL + "/too/large"); entry != nil {
+		t.Error("expected the large response not to be stored")
+	}
+
+	// without a limit in the Config the whole body is still returned
+	res, body, err := DoWithConfig(client, Config{}, "Test", mustRequest(t, server.URL+"/too/large"), "")
+	if err != nil || res.StatusCode != 200 || body != `{"path":"/too/large"}` {
+		t.Errorf("expected the full response, got %s %v", body, err)
+	}
+}
+
+func TestMemoryCacheStorageEvictsLeastRecentlyUsed(t *testing.T) {
+	storage := NewMemoryCacheStorage(2)
+	storage.SetEntry("a", &CacheEntry{Body: []byte("a")})
+	storage.SetEntry("b", &CacheEntry{Body: []byte("b")})
+	storage.Entry("a")
+	storage.SetEntry("c", &CacheEntry{Body: []byte("c")})
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		entry, _ := storage.Entry(key)
+		if (entry != nil) != expected {
+			t.Errorf("expected entry %s present %t", key, expected)
+		}
+	}
+}
+
+func mustRequest(t *testing.T, url string) *http.Request {
+	req, err := BuildRequest("test", "application/json", "GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
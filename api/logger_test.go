@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gokitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/levels"
+)
+
+func TestJSONLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	config := Config{OriginID: "origin", Logger: JSONLogger(&buf), Redact: []string{"secretkey"}}
+	req, err := BuildRequest("test", "application/json", "POST", server.URL+"/secretkey/v1/event", strings.NewReader(`{"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Basic c2VjcmV0")
+	if _, _, err = DoWithConfig(http.DefaultClient, config, "Event", req, `{"a":1}`); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("expected a single line of JSON, see %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "secretkey") || strings.Contains(buf.String(), "c2VjcmV0") {
+		t.Errorf("expected credentials to be redacted, see %s", buf.String())
+	}
+	var entry struct {
+		OriginID       string              `json:"origin_id"`
+		Method         string              `json:"method"`
+		URL            string              `json:"url"`
+		StatusCode     int                 `json:"status_code"`
+		RequestBytes   int64               `json:"request_bytes"`
+		ResponseBytes  int64               `json:"response_bytes"`
+		ResponseHeader map[string][]string `json:"response_header"`
+		Error          *string             `json:"error"`
+		ResponseBody   string              `json:"response_body"`
+	}
+	if err = json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.OriginID != "origin" || entry.Method != "POST" || entry.StatusCode != 200 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.URL != server.URL+"/REDACTED/v1/event" {
+		t.Errorf("expected redacted url, got %s", entry.URL)
+	}
+	if entry.RequestBytes != 7 || entry.ResponseBytes != 11 || entry.ResponseBody != `{"ok":true}` {
+		t.Errorf("unexpected body details %+v", entry)
+	}
+	if entry.ResponseHeader["Content-Type"][0] != "application/json" {
+		t.Errorf("expected response headers, got %v", entry.ResponseHeader)
+	}
+	if entry.Error != nil {
+		t.Errorf("expected null error, got %s", *entry.Error)
+	}
+}
+
+func TestLoggersRedactCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer server.Close()
+
+	for name, logger := range map[string]func(w io.Writer) Logger{"BasicLogger": BasicLogger, "StructuredLogger": StructuredLogger, "JSONLogger": JSONLogger} {
+		var buf bytes.Buffer
+		config := Config{Logger: logger(&buf), Redact: []string{"secretkey"}}
+		for _, base := range []string{server.URL, closed.URL} {
+			req, err := BuildRequest("test", "application/json", "GET", base+"/secretkey/v1/event", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Basic c2VjcmV0")
+			DoWithConfig(http.DefaultClient, config, "Event", req, "")
+		}
+		if strings.Count(buf.String(), "\n") != 2 {
+			t.Fatalf("%s expected 2 calls to be logged, see %s", name, buf.String())
+		}
+		if strings.Contains(buf.String(), "secretkey") || strings.Contains(buf.String(), "c2VjcmV0") {
+			t.Errorf("%s expected credentials to be redacted, see %s", name, buf.String())
+		}
+		if !strings.Contains(buf.String(), "/REDACTED/v1/event") {
+			t.Errorf("%s expected the redacted request to be logged, see %s", name, buf.String())
+		}
+	}
+}
+
+func TestLeveledLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := LeveledLogger(levels.New(gokitlog.NewLogfmtLogger(&buf)), LevelOptions{BodyLevel: LevelError})
+
+	logger.Log(Call{CalleeID: "Event", StatusCode: 200, ResBody: "success body"})
+	logger.Log(Call{CalleeID: "Event", StatusCode: 404, ResBody: "failure body"})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, see %s", buf.String())
+	}
+	if !strings.Contains(lines[0], "level=info") || strings.Contains(lines[0], "success body") {
+		t.Errorf("expected info without body, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=error") || !strings.Contains(lines[1], "failure body") {
+		t.Errorf("expected error with body, got %s", lines[1])
+	}
+
+	buf.Reset()
+	logger = LeveledLogger(levels.New(gokitlog.NewLogfmtLogger(&buf)), LevelOptions{MinLevel: LevelError})
+	logger.Log(Call{CalleeID: "Event", StatusCode: 200})
+	if buf.Len() != 0 {
+		t.Errorf("expected successful call to be filtered, got %s", buf.String())
+	}
+}
+
+func TestBasicLoggerTenantID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := BasicLogger(&buf)
+	logger.Log(Call{OriginID: "origin", CalleeID: "Event"})
+	if strings.Contains(buf.String(), "TenantID") {
+		t.Errorf("expected no tenant column without a tenant, see %s", buf.String())
+	}
+	buf.Reset()
+	logger.Log(Call{OriginID: "origin", TenantID: "charity1", CalleeID: "Event"})
+	if !strings.Contains(buf.String(), "OriginID: origin\tTenantID: charity1\tDuration:") {
+		t.Errorf("expected the tenant column, see %s", buf.String())
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error classes reported in a Metric
+const (
+	ErrorClassCircuitOpen = "circuit_open"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassTransport   = "transport"
+	ErrorClassRead        = "read"
+	ErrorClassClient      = "client_error"
+	ErrorClassServer      = "server_error"
+)
+
+// Metric defines the measurements of a single API request
+//
+// StatusCode is 0 when no response was received, Retries is the number of times the caller had already tried the request (see Config.Retries)
+// and ErrorClass is empty for successful requests, otherwise one of the ErrorClass constants
+type Metric struct {
+	CalleeID   string
+	Method     string
+	StatusCode int
+	Duration   time.Duration
+	Retries    int
+	ErrorClass string
+}
+
+// Metrics provides an interface for observing API requests, e.g. to record request counts and latencies
+type Metrics interface {
+	Observe(m Metric)
+}
+
+// MetricsFunc provides a type for single function implementations of the Metrics interface
+type MetricsFunc func(m Metric)
+
+// Observe defines the single method Metrics interface
+func (f MetricsFunc) Observe(m Metric) {
+	f(m)
+}
+
+// ClassifyError returns the ErrorClass of an API request from its response and error
+func ClassifyError(res *http.Response, err error) string {
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) {
+			return ErrorClassCircuitOpen
+		}
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return ErrorClassTimeout
+		}
+		if res == nil {
+			return ErrorClassTransport
+		}
+		return ErrorClassRead
+	}
+	if res == nil {
+		return ""
+	}
+	if res.StatusCode >= 500 {
+		return ErrorClassServer
+	}
+	if res.StatusCode >= 400 {
+		return ErrorClassClient
+	}
+	return ""
+}
+
+// DefaultBuckets are the request duration histogram buckets used by PrometheusMetrics, in seconds
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20}
+
+// PrometheusMetrics is a Metrics implementation recording request counts and a request duration histogram per callee,
+// exposed in the Prometheus text format by its http.Handler
+type PrometheusMetrics struct {
+	namespace string
+	buckets   []float64
+
+	mu         sync.Mutex
+	requests   map[requestsKey]uint64
+	histograms map[durationKey]*histogram
+}
+
+type requestsKey struct {
+	callee, method, code, errorClass string
+}
+
+type durationKey struct {
+	callee, method string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusMetrics instantiates PrometheusMetrics with metric names prefixed by namespace, if buckets are not provided DefaultBuckets are used
+func NewPrometheusMetrics(namespace string, buckets []float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	return &PrometheusMetrics{
+		namespace:  namespace,
+		buckets:    b,
+		requests:   make(map[requestsKey]uint64),
+		histograms: make(map[durationKey]*histogram),
+	}
+}
+
+// Observe records the Metric
+func (p *PrometheusMetrics) Observe(m Metric) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[requestsKey{m.CalleeID, m.Method, strconv.Itoa(m.StatusCode), m.ErrorClass}]++
+	dk := durationKey{m.CalleeID, m.Method}
+	h, exists := p.histograms[dk]
+	if !exists {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.histograms[dk] = h
+	}
+	secs := m.Duration.Seconds()
+	for i, le := range p.buckets {
+		if secs <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += secs
+	h.count++
+}
+
+// ServeHTTP writes the recorded metrics in the Prometheus text exposition format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	p.WriteTo(w)
+}
+
+// WriteTo writes the recorded metrics in the Prometheus text exposition format to w
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var b strings.Builder
+
+	name := p.name("requests_total")
+	fmt.Fprintf(&b, "# HELP %s Total number of API requests.\n# TYPE %s counter\n", name, name)
+	rks := make([]requestsKey, 0, len(p.requests))
+	for k := range p.requests {
+		rks = append(rks, k)
+	}
+	sort.Slice(rks, func(i, j int) bool {
+		a, b := rks[i], rks[j]
+		return a.callee+a.method+a.code+a.errorClass < b.callee+b.method+b.code+b.errorClass
+	})
+	for _, k := range rks {
+		fmt.Fprintf(&b, "%s{callee=%q,method=%q,code=%q,error_class=%q} %d\n", name, k.callee, k.method, k.code, k.errorClass, p.requests[k])
+	}
+
+	name = p.name("request_duration_seconds")
+	fmt.Fprintf(&b, "# HELP %s API request latencies in seconds.\n# TYPE %s histogram\n", name, name)
+	dks := make([]durationKey, 0, len(p.histograms))
+	for k := range p.histograms {
+		dks = append(dks, k)
+	}
+	sort.Slice(dks, func(i, j int) bool {
+		return dks[i].callee+dks[i].method < dks[j].callee+dks[j].method
+	})
+	for _, k := range dks {
+		h := p.histograms[k]
+		labels := fmt.Sprintf("callee=%q,method=%q", k.callee, k.method)
+		for i, le := range p.buckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%q} %d\n", name, labels, strconv.FormatFloat(le, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (p *PrometheusMetrics) name(metric string) string {
+	if p.namespace == "" {
+		return metric
+	}
+	return p.namespace + "_" + metric
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	pm := NewPrometheusMetrics("justin", []float64{1, 0.5})
+	config := Config{Metrics: pm}
+	for _, path := range []string{"/", "/", "/missing"} {
+		req, err := BuildRequest("test", "application/json", "GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err = DoWithConfig(http.DefaultClient, config, "Event", req, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pm.Observe(Metric{CalleeID: "Event", Method: "GET", Duration: 750 * time.Millisecond, ErrorClass: ErrorClassTimeout})
+
+	rec := httptest.NewRecorder()
+	pm.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	for _, expected := range []string{
+		"# TYPE justin_requests_total counter\n",
+		`justin_requests_total{callee="Event",method="GET",code="200",error_class=""} 2` + "\n",
+		`justin_requests_total{callee="Event",method="GET",code="404",error_class="client_error"} 1` + "\n",
+		`justin_requests_total{callee="Event",method="GET",code="0",error_class="timeout"} 1` + "\n",
+		"# TYPE justin_request_duration_seconds histogram\n",
+		`justin_request_duration_seconds_bucket{callee="Event",method="GET",le="0.5"} 3` + "\n",
+		`justin_request_duration_seconds_bucket{callee="Event",method="GET",le="1"} 4` + "\n",
+		`justin_request_duration_seconds_bucket{callee="Event",method="GET",le="+Inf"} 4` + "\n",
+		`justin_request_duration_seconds_count{callee="Event",method="GET"} 4` + "\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected metrics to contain %q, see\n%s", expected, out)
+		}
+	}
+}
@@ -2,17 +2,21 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
 
 	gokitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/levels"
 )
 
 // RequestTemplates provides a cache of RequestTemplates
@@ -26,8 +30,13 @@ type RequestTemplate struct {
 }
 
 // Call defines an API call - for logging
+//
+// The string fields are preformatted for simple loggers, the typed fields (Method onwards) provide a structured record of the call.
+// The URL and headers (including those in Req and Res) and Err have any credentials redacted, StatusCode is 0 when no response was received.
+// Error is the original error, which may include the unredacted URL.
 type Call struct {
 	OriginID  string
+	TenantID  string
 	CalleeID  string
 	TimeTaken string
 	Req       string
@@ -35,6 +44,16 @@ type Call struct {
 	Res       string
 	ResBody   string
 	Err       string
+
+	Method         string
+	URL            string
+	StatusCode     int
+	RequestHeader  http.Header
+	ResponseHeader http.Header
+	Duration       time.Duration
+	RequestBytes   int64
+	ResponseBytes  int64
+	Error          error
 }
 
 // Logger provides an interface for logging API calls
@@ -54,7 +73,12 @@ func (f LoggerFunc) Log(apiCall Call) {
 func BasicLogger(w io.Writer) Logger {
 	var logger LoggerFunc
 	logger = func(c Call) {
-		m := fmt.Sprintf("OriginID: %s\tDuration: %s ms\tMethod: %s", c.OriginID, c.TimeTaken, c.CalleeID)
+		m := fmt.Sprintf("OriginID: %s", c.OriginID)
+		if c.TenantID != "" {
+			// only calls made by a Service in a Registry have a tenant
+			m += fmt.Sprintf("\tTenantID: %s", c.TenantID)
+		}
+		m += fmt.Sprintf("\tDuration: %s ms\tMethod: %s", c.TimeTaken, c.CalleeID)
 		fmt.Fprint(w, "API_CALL\t"+m+fmt.Sprintf("\tRequest: %s\tRequestBody: %s\tResponse %s\tResponseBody: %s\tError: %s\n", c.Req, c.ReqBody, c.Res, c.ResBody, c.Err))
 	}
 	return logger
@@ -67,11 +91,114 @@ func StructuredLogger(w io.Writer) Logger {
 	l := gokitlog.NewLogfmtLogger(w)
 	var logger LoggerFunc
 	logger = func(c Call) {
-		l.Log("msg", "calling api", "origin_id", c.OriginID, "duration", c.TimeTaken, "method", c.CalleeID, "request", fmt.Sprintf("%#v", c.Req), "request_body", c.ReqBody, "response", fmt.Sprintf("%#v", c.Res), "response_body", c.ResBody, "error", c.Err)
+		l.Log("msg", "calling api", "origin_id", c.OriginID, "tenant_id", c.TenantID, "duration", c.TimeTaken, "method", c.CalleeID, "request", fmt.Sprintf("%#v", c.Req), "request_body", c.ReqBody, "response", fmt.Sprintf("%#v", c.Res), "response_body", c.ResBody, "error", c.Err)
+	}
+	return logger
+}
+
+// JSONLogger provides a Logger implementation writing each Call as a line of JSON with the provided io.Writer
+// Uses go-kit log, see https://github.com/go-kit/kit/tree/master/log
+func JSONLogger(w io.Writer) Logger {
+	l := gokitlog.NewJSONLogger(w)
+	var logger LoggerFunc
+	logger = func(c Call) {
+		l.Log(callKeyvals(c, true)...)
+	}
+	return logger
+}
+
+// Level is the severity of a logged Call
+type Level int
+
+const (
+	// LevelDebug is the lowest Level
+	LevelDebug Level = iota
+	// LevelInfo is the Level of successful calls
+	LevelInfo
+	// LevelError is the Level of failed calls, i.e. those returning an error or a 4xx/5xx response
+	LevelError
+)
+
+// LevelOptions contains settings for filtering the calls logged by a LeveledLogger.
+//
+// MinLevel is the lowest Level logged, by default every call is logged.
+//
+// BodyLevel is the lowest Level logged with request and response bodies, e.g. LevelError only logs bodies on error.
+type LevelOptions struct {
+	MinLevel  Level
+	BodyLevel Level
+}
+
+// LeveledLogger provides a Logger implementation using the go-kit levels package, filtered with the provided LevelOptions
+// Successful calls are logged at info and failed calls at error, see https://github.com/go-kit/kit/tree/master/log/levels
+func LeveledLogger(l levels.Levels, opts LevelOptions) Logger {
+	var logger LoggerFunc
+	logger = func(c Call) {
+		level := CallLevel(c)
+		if level < opts.MinLevel {
+			return
+		}
+		keyvals := callKeyvals(c, level >= opts.BodyLevel)
+		switch level {
+		case LevelError:
+			l.Error().Log(keyvals...)
+		case LevelInfo:
+			l.Info().Log(keyvals...)
+		default:
+			l.Debug().Log(keyvals...)
+		}
 	}
 	return logger
 }
 
+// CallLevel returns the Level of the Call, LevelError if it returned an error or a 4xx/5xx response otherwise LevelInfo
+func CallLevel(c Call) Level {
+	if c.Error != nil || c.StatusCode >= 400 {
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// callKeyvals returns the structured fields of the Call as go-kit log key value pairs
+func callKeyvals(c Call, bodies bool) []interface{} {
+	keyvals := []interface{}{
+		"msg", "calling api",
+		"origin_id", c.OriginID,
+		"tenant_id", c.TenantID,
+		"callee_id", c.CalleeID,
+		"method", c.Method,
+		"url", c.URL,
+		"status_code", c.StatusCode,
+		"duration_ms", float64(c.Duration) / float64(time.Millisecond),
+		"request_bytes", c.RequestBytes,
+		"response_bytes", c.ResponseBytes,
+		"request_header", c.RequestHeader,
+		"response_header", c.ResponseHeader,
+		"error", nil,
+	}
+	if c.Error != nil {
+		// the error may include the URL, so is logged redacted
+		keyvals[len(keyvals)-1] = c.Err
+	}
+	if bodies {
+		keyvals = append(keyvals, "request_body", c.ReqBody, "response_body", c.ResBody)
+	}
+	return keyvals
+}
+
+// TenantLogger wraps the provided Logger, tagging every Call with the specified tenant
+func TenantLogger(tenantID string, logger Logger) Logger {
+	if logger == nil {
+		return nil
+	}
+	var tl LoggerFunc
+	tl = func(c Call) {
+		c.TenantID = tenantID
+		logger.Log(c)
+	}
+	return tl
+}
+
 // BuildBody returns the body of an API request built from the specified template and data
 func BuildBody(templateName string, data interface{}, contentType string) (string, io.Reader, error) {
 	rt := RequestTemplates[templateName]
@@ -110,15 +237,115 @@ func BuildRequest(userAgent string, contentType string, method string, reqPath s
 	return req, nil
 }
 
+// Config contains optional settings for transporting API requests with DoWithConfig
+//
+// OriginID and Logger are as used by Do, MaxResponseBytes is an optional limit on the size of response bodies, Redact lists secrets (e.g. the API key) replaced in the URL of each logged Call, Breaker is an optional CircuitBreaker to fail requests fast while the API is failing
+// and Metrics is an optional implementation of the Metrics interface observing every request.
+//
+// Tracer is an optional implementation of the Tracer interface, when set (or the request context is already part of a trace)
+// a Span is started for every request and propagated with a W3C traceparent header.
+// An origin carried by the request context (see WithOrigin) takes precedence over OriginID.
+//
+// Sensitive marks a request or response which carries credentials (e.g. an OAuth2 token exchange), neither body is captured for the Logger
+// and the Call omits the raw request and response.
+//
+// Retries is the number of times the caller has already tried the request, e.g. 1 for the second attempt, reported in the Metric.
+type Config struct {
+	OriginID         string
+	Logger           Logger
+	Redact           []string
+	MaxResponseBytes int64
+	Breaker          *CircuitBreaker
+	Metrics          Metrics
+	Tracer           Tracer
+	Sensitive        bool
+	Retries          int
+}
+
 // Do transports a single API request
 func Do(client *http.Client, originID string, calleeID string, req *http.Request, reqBody string, logger Logger) (res *http.Response, readBody string, err error) {
+	return DoWithConfig(client, Config{OriginID: originID, Logger: logger}, calleeID, req, reqBody)
+}
+
+// ErrResponseTooLarge is returned when a response body exceeds the MaxResponseBytes of the Config
+var ErrResponseTooLarge = errors.New("response too large")
+
+// DecodeError is returned by DoAndDecode when a response body is not the expected JSON
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("invalid response %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DoWithConfig transports a single API request using the specified Config
+func DoWithConfig(client *http.Client, config Config, calleeID string, req *http.Request, reqBody string) (res *http.Response, readBody string, err error) {
+	return do(client, config, calleeID, req, reqBody, nil)
+}
+
+// DoAndDecode transports a single API request using the specified Config, streaming a successful (2xx) JSON response body straight into v
+//
+// The body of a successful response is only returned when the Config has a Logger and is not Sensitive, the body of any other response is always returned.
+func DoAndDecode(client *http.Client, config Config, calleeID string, req *http.Request, reqBody string, v interface{}) (res *http.Response, readBody string, err error) {
+	return do(client, config, calleeID, req, reqBody, v)
+}
+
+func do(client *http.Client, config Config, calleeID string, req *http.Request, reqBody string, v interface{}) (res *http.Response, readBody string, err error) {
+	originID, logger := config.OriginID, config.Logger
+	if origin := OriginFromContext(req.Context()); origin != "" {
+		originID = origin
+	}
 	start := time.Now()
 	readBody = ""
+	if config.Tracer != nil || Traced(req.Context()) {
+		var span *Span
+		var ctx context.Context
+		ctx, span = StartSpan(req.Context(), config.Tracer, calleeID)
+		span.OriginID = originID
+		span.SetAttribute("http.method", req.Method)
+		req = req.WithContext(ctx)
+		req.Header.Set("traceparent", span.Traceparent())
+		defer func() {
+			if res != nil {
+				span.SetAttribute("http.status_code", strconv.Itoa(res.StatusCode))
+			}
+			span.Finish(err)
+		}()
+	}
+	if config.Metrics != nil {
+		defer func() {
+			m := Metric{CalleeID: calleeID, Method: req.Method, Duration: time.Since(start), Retries: config.Retries, ErrorClass: ClassifyError(res, err)}
+			if res != nil {
+				m.StatusCode = res.StatusCode
+			}
+			config.Metrics.Observe(m)
+		}()
+	}
+	if config.Breaker != nil {
+		var token CircuitToken
+		if token, err = config.Breaker.Allow(calleeID); err != nil {
+			if logger != nil {
+				logger.Log(newCall(config, originID, calleeID, 0, req, reqBody, res, readBody, err))
+			}
+			return nil, readBody, err
+		}
+		defer func() {
+			// the API responded even if the body was too large or not as expected
+			var decodeErr *DecodeError
+			responded := err == nil || errors.As(err, &decodeErr) || errors.Is(err, ErrResponseTooLarge)
+			config.Breaker.Done(token, responded && res != nil && res.StatusCode < 500)
+		}()
+	}
 	res, err = client.Do(req)
 	if err != nil {
 		if logger != nil {
-			timeTaken := strconv.FormatFloat(time.Since(start).Seconds()*1000, 'f', 2, 64)
-			logger.Log(Call{OriginID: originID, CalleeID: calleeID, TimeTaken: timeTaken, Req: fmt.Sprintf("%v", req), ReqBody: reqBody, Res: fmt.Sprintf("%v", res), ResBody: readBody, Err: fmt.Sprintf("%v", err)})
+			logger.Log(newCall(config, originID, calleeID, time.Since(start), req, reqBody, res, readBody, err))
 		}
 		return res, readBody, err
 	}
@@ -128,17 +355,116 @@ func Do(client *http.Client, originID string, calleeID string, req *http.Request
 			res.Body.Close()
 		}
 	}()
-	var buffer []byte
-	buffer, err = ioutil.ReadAll(res.Body)
-	readBody = string(buffer)
+	body := &limitedReader{r: res.Body, limit: config.MaxResponseBytes}
+	if v != nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+		// only capture the body when it will be logged
+		var captured bytes.Buffer
+		var r io.Reader = body
+		if logger != nil && !config.Sensitive {
+			r = io.TeeReader(body, &captured)
+		}
+		if err = json.NewDecoder(r).Decode(v); err == nil {
+			// drain the body so the connection can be re-used
+			_, err = io.Copy(ioutil.Discard, r)
+		} else if !errors.Is(err, ErrResponseTooLarge) {
+			err = &DecodeError{Err: err}
+		}
+		readBody = captured.String()
+	} else {
+		var buffer []byte
+		buffer, err = ioutil.ReadAll(body)
+		readBody = string(buffer)
+	}
 	if logger != nil {
-		timeTaken := strconv.FormatFloat(time.Since(start).Seconds()*1000, 'f', 2, 64)
-		logger.Log(Call{OriginID: originID, CalleeID: calleeID, TimeTaken: timeTaken, Req: fmt.Sprintf("%v", req), ReqBody: reqBody, Res: fmt.Sprintf("%v", res), ResBody: readBody, Err: fmt.Sprintf("%v", err)})
+		c := newCall(config, originID, calleeID, time.Since(start), req, reqBody, res, readBody, err)
+		c.ResponseBytes = body.read
+		logger.Log(c)
 	}
 
 	return res, readBody, err
 }
 
+// newCall builds the Call logged for a request, Req and Res are formatted from the redacted URL and headers
+func newCall(config Config, originID string, calleeID string, duration time.Duration, req *http.Request, reqBody string, res *http.Response, resBody string, err error) Call {
+	c := Call{
+		OriginID:      originID,
+		CalleeID:      calleeID,
+		TimeTaken:     strconv.FormatFloat(duration.Seconds()*1000, 'f', 2, 64),
+		ReqBody:       reqBody,
+		Res:           "<nil>",
+		ResBody:       resBody,
+		Err:           redact(fmt.Sprintf("%v", err), config.Redact),
+		Method:        req.Method,
+		URL:           redact(req.URL.String(), config.Redact),
+		RequestHeader: redactHeader(req.Header),
+		Duration:      duration,
+		RequestBytes:  int64(len(reqBody)),
+		ResponseBytes: int64(len(resBody)),
+		Error:         err,
+	}
+	c.Req = fmt.Sprintf("%s %s %v", c.Method, c.URL, c.RequestHeader)
+	if res != nil {
+		c.StatusCode = res.StatusCode
+		c.ResponseHeader = redactHeader(res.Header)
+		c.Res = fmt.Sprintf("%s %v", res.Status, c.ResponseHeader)
+	}
+	if config.Sensitive {
+		c.Req, c.ReqBody, c.Res, c.ResBody = "", "", "", ""
+	}
+	return c
+}
+
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, "REDACTED", -1)
+		}
+	}
+	return s
+}
+
+func redactHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	result := make(http.Header, len(h))
+	for k, v := range h {
+		if k == "Authorization" || k == "Set-Cookie" || k == "Cookie" {
+			result[k] = []string{"REDACTED"}
+			continue
+		}
+		result[k] = append([]string(nil), v...)
+	}
+	return result
+}
+
+// limitedReader reads up to limit bytes, returning ErrResponseTooLarge if there are more (a limit of 0 is unlimited)
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.limit > 0 {
+		if l.read >= l.limit {
+			// check whether the body has ended before reporting it too large
+			var b [1]byte
+			n, err := l.r.Read(b[:])
+			if n > 0 {
+				return 0, fmt.Errorf("%w, over %d bytes", ErrResponseTooLarge, l.limit)
+			}
+			return 0, err
+		}
+		if int64(len(p)) > l.limit-l.read {
+			p = p[:l.limit-l.read]
+		}
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	return n, err
+}
+
 type batchedRequest struct {
 	Sequence int
 	Request  *http.Request
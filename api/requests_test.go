@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDoAndDecode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Write([]byte(`{"names":["` + strings.Repeat("a", 100) + `"]}`))
+		case "/invalid":
+			w.Write([]byte(`not json`))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		default:
+			w.Write([]byte(`{"names":["a","b"]}`))
+		}
+	}))
+	defer server.Close()
+
+	do := func(config Config, path string) (*http.Response, string, []string, error) {
+		req, err := BuildRequest("test", "application/json", "GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result struct {
+			Names []string `json:"names"`
+		}
+		res, body, err := DoAndDecode(http.DefaultClient, config, "Suggest", req, "", &result)
+		return res, body, result.Names, err
+	}
+
+	_, body, names, err := do(Config{MaxResponseBytes: 50}, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || body != "" {
+		t.Errorf("expected 2 names and no captured body without a logger, got %v %q", names, body)
+	}
+
+	var logged Call
+	_, body, _, err = do(Config{Logger: LoggerFunc(func(c Call) { logged = c })}, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != `{"names":["a","b"]}` || logged.ResBody != body || logged.ResponseBytes != int64(len(body)) {
+		t.Errorf("expected body to be captured for logging, got %q %+v", body, logged)
+	}
+
+	if _, _, _, err = do(Config{MaxResponseBytes: 50}, "/large"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+
+	var decodeErr *DecodeError
+	if _, _, _, err = do(Config{}, "/invalid"); !errors.As(err, &decodeErr) {
+		t.Errorf("expected DecodeError, got %v", err)
+	}
+
+	res, body, names, err := do(Config{}, "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 404 || names != nil || body != `{"error":"not found"}` {
+		t.Errorf("expected unsuccessful response body to be returned undecoded, got %d %v %q", res.StatusCode, names, body)
+	}
+}
+
+func TestDoWithConfigMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	for limit, expectErr := range map[int64]bool{0: false, 100: false, 99: true} {
+		req, err := BuildRequest("test", "application/json", "GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = DoWithConfig(http.DefaultClient, Config{MaxResponseBytes: limit}, "Test", req, "")
+		if errors.Is(err, ErrResponseTooLarge) != expectErr {
+			t.Errorf("limit %d, unexpected error %v", limit, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"text/template"
 )
 
@@ -26,32 +27,67 @@ const accountRegistrationTmpl = `{
 const registerFundraisingPageForEventTmpl = `{
   "charityId": {{.CharityID}},
   "eventId": {{.EventID}},
-  "pageShortName": "{{.PageShortName}}",
-  "pageTitle": "{{.PageTitle}}",
-  "targetAmount": "{{.TargetAmount}}",
+  "pageShortName": {{json .PageShortName}},
+  "pageTitle": {{json .PageTitle}},
+  "targetAmount": {{if .Target}}{{json .Target}}{{else}}{{json .TargetAmount}}{{end}},
   "justGivingOptIn": {{.JustGivingOptIn}},
   "charityOptIn": {{.CharityOptIn}},
   "charityFunded": {{.CharityFunded}},
-  "pageStory": "{{.PageStory}}",
-  "customCodes": {
-    "customCode1": "{{index .CustomCodes 0}}",
-    "customCode2": "{{index .CustomCodes 1}}",
-    "customCode3": "{{index .CustomCodes 2}}",
-    "customCode4": "{{index .CustomCodes 3}}",
-    "customCode5": "{{index .CustomCodes 4}}",
-    "customCode6": "{{index .CustomCodes 5}}"
-  },{{ if gt (len .Images) 0 }}"images": [
-    {{range $i, $v := .Images}}{{if ne $i 0}},{{end}}{"caption": "{{$v.Caption}}","url": "{{$v.URL}}","isDefault": "{{eq $i 0}}"}{{ end }}
+  "pageStory": {{json .PageStory}},{{ if .CustomCodes }}
+  "customCodes": {{json .CustomCodes}},{{ end }}{{ if gt (len .Images) 0 }}"images": [
+    {{$default := .DefaultImage}}{{range $i, $v := .Images}}{{if ne $i 0}},{{end}}{"caption": {{json $v.Caption}},"url": {{json $v.URL.String}},"isDefault": {{eq $i $default}}}{{ end }}
+    ],{{ end }}{{ if .ActivityType }}
+  "activityType": {{json .ActivityType}},{{ end }}{{ if .EventName }}
+  "eventName": {{json .EventName}},{{ end }}{{ if not .EventDate.IsZero }}
+  "eventDate": {{json .EventDate}},{{ end }}{{ if not .ExpiryDate.IsZero }}
+  "expiryDate": {{json .ExpiryDate}},{{ end }}{{ if .Reference }}
+  "reference": {{json .Reference}},{{ end }}{{ with .RememberedPerson }}
+  "rememberedPersonReference": {
+    {{ with $.RememberedPersonRelationship }}"relationship": {{json .}},{{ end }}
+    "rememberedPerson": {{json .}}
+  },{{ end }}{{ if .Tags }}
+  "tags": {{json .Tags}},{{ end }}
+  "currency": {{with .Target}}{{json (or .Currency $.CurrencyCode)}}{{else}}{{json .CurrencyCode}}{{end}}{{ if gt .TeamID 0 }},
+  "teamId": {{.TeamID}}{{ end }}
+}`
+
+const registerFundraisingPageForCampaignTmpl = `{
+  "campaignGuid": {{json .CampaignID}},
+  "charityId": {{.CharityID}},
+  "pageShortName": {{json .PageShortName}},
+  "pageTitle": {{json .PageTitle}},
+  "targetAmount": {{if .Target}}{{json .Target}}{{else}}{{json .TargetAmount}}{{end}},
+  "justGivingOptIn": {{.JustGivingOptIn}},
+  "charityOptIn": {{.CharityOptIn}},
+  "charityFunded": {{.CharityFunded}},
+  "pageStory": {{json .PageStory}},{{ if .CustomCodes }}
+  "customCodes": {{json .CustomCodes}},{{ end }}{{ if gt (len .Images) 0 }}"images": [
+    {{$default := .DefaultImage}}{{range $i, $v := .Images}}{{if ne $i 0}},{{end}}{"caption": {{json $v.Caption}},"url": {{json $v.URL.String}},"isDefault": {{eq $i $default}}}{{ end }}
     ],{{ end }}
-  "currency": "{{.CurrencyCode}}"{{ if gt .TeamID 0 }},
+  "currency": {{with .Target}}{{json (or .Currency $.CurrencyCode)}}{{else}}{{json .CurrencyCode}}{{end}}{{ if gt .TeamID 0 }},
   "teamId": {{.TeamID}}{{ end }}
 }`
 
+const addOfflineDonationTmpl = `{
+  "amount": {{json .Amount}},
+  "currencyCode": {{json .CurrencyCode}},
+  "donorName": {{json .DonorName}},
+  "message": {{json .Message}}
+}`
+
 const validateTmpl = `{
     "email": "{{.Email}}",
     "password": "{{.Password}}"
 }`
 
+// templateFuncs are available to the request templates, json encodes a value as JSON
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
 func init() {
 	// Cache request templates
 	RequestTemplates = make(map[string]RequestTemplate)
@@ -65,6 +101,14 @@ func init() {
 	RequestTemplates["AccountRegistration"] = accountRegistration
 	// RegisterFundraisingPageForEvent
 	registerFundraisingPageForEvent := RequestTemplate{}
-	registerFundraisingPageForEvent.t, registerFundraisingPageForEvent.err = template.New("registerFundraisingPageForEventTmpl").Parse(registerFundraisingPageForEventTmpl)
+	registerFundraisingPageForEvent.t, registerFundraisingPageForEvent.err = template.New("registerFundraisingPageForEventTmpl").Funcs(templateFuncs).Parse(registerFundraisingPageForEventTmpl)
 	RequestTemplates["RegisterFundraisingPageForEvent"] = registerFundraisingPageForEvent
+	// RegisterFundraisingPageForCampaign
+	registerFundraisingPageForCampaign := RequestTemplate{}
+	registerFundraisingPageForCampaign.t, registerFundraisingPageForCampaign.err = template.New("registerFundraisingPageForCampaignTmpl").Funcs(templateFuncs).Parse(registerFundraisingPageForCampaignTmpl)
+	RequestTemplates["RegisterFundraisingPageForCampaign"] = registerFundraisingPageForCampaign
+	// AddOfflineDonation
+	addOfflineDonation := RequestTemplate{}
+	addOfflineDonation.t, addOfflineDonation.err = template.New("addOfflineDonationTmpl").Funcs(templateFuncs).Parse(addOfflineDonationTmpl)
+	RequestTemplates["AddOfflineDonation"] = addOfflineDonation
 }
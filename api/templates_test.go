@@ -0,0 +1,27 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAddOfflineDonationBody(t *testing.T) {
+	donation := struct {
+		Amount       string
+		CurrencyCode string
+		DonorName    string
+		Message      string
+	}{"10.50", "GBP", `Jo "JJ" Smith\`, `Well done", "amount": "1000`}
+
+	body, _, err := BuildBody("AddOfflineDonation", donation, "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]string
+	if err = json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("expected valid JSON, got %s, %v", body, err)
+	}
+	if len(result) != 4 || result["amount"] != "10.50" || result["donorName"] != donation.DonorName || result["message"] != donation.Message {
+		t.Errorf("expected the donation fields unchanged, got %s", body)
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	mathrand "math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, as defined by W3C Trace Context
+type TraceID [16]byte
+
+// String returns the TraceID as lowercase hex
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid reports whether the TraceID is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// SpanID identifies a span within a trace, as defined by W3C Trace Context
+type SpanID [8]byte
+
+// String returns the SpanID as lowercase hex
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid reports whether the SpanID is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// TraceFlags are the W3C Trace Context trace-flags of a trace
+type TraceFlags byte
+
+// TraceFlagsSampled is set when the trace may have been recorded by the caller
+const TraceFlagsSampled TraceFlags = 0x01
+
+// String returns the TraceFlags as lowercase hex
+func (f TraceFlags) String() string {
+	return hex.EncodeToString([]byte{byte(f)})
+}
+
+// Sampled reports whether the sampled flag is set
+func (f TraceFlags) Sampled() bool {
+	return f&TraceFlagsSampled != 0
+}
+
+// Span represents a unit of work within a trace, e.g. a single API request
+//
+// Flags are carried from the parent, a new trace is sampled.
+type Span struct {
+	Name     string
+	TraceID  TraceID
+	SpanID   SpanID
+	ParentID SpanID
+	Flags    TraceFlags
+	OriginID string
+	Start    time.Time
+	End      time.Time
+	Err      error
+
+	tracer     Tracer
+	mu         sync.Mutex
+	attributes map[string]string
+	ended      bool
+}
+
+// Tracer provides an interface for receiving spans, e.g. to export them to a tracing system
+type Tracer interface {
+	SpanStarted(span *Span)
+	SpanEnded(span *Span)
+}
+
+// SetAttribute records a key value pair against the Span
+func (s *Span) SetAttribute(key string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attributes == nil {
+		s.attributes = make(map[string]string)
+	}
+	s.attributes[key] = value
+}
+
+// Attributes returns a copy of the key value pairs recorded against the Span
+func (s *Span) Attributes() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]string, len(s.attributes))
+	for k, v := range s.attributes {
+		result[k] = v
+	}
+	return result
+}
+
+// Finish ends the Span, recording err if the work failed
+func (s *Span) Finish(err error) {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.Err = err
+	s.mu.Unlock()
+	if s.tracer != nil {
+		s.tracer.SpanEnded(s)
+	}
+}
+
+// Traceparent returns the W3C traceparent header value identifying the Span
+func (s *Span) Traceparent() string {
+	return "00-" + s.TraceID.String() + "-" + s.SpanID.String() + "-" + s.Flags.String()
+}
+
+type spanKey struct{}
+
+type remoteParentKey struct{}
+
+type originKey struct{}
+
+type remoteParent struct {
+	traceID TraceID
+	spanID  SpanID
+	flags   TraceFlags
+}
+
+// StartSpan starts a Span named name, as a child of any Span (or traceparent) in ctx, otherwise as the root of a new trace
+//
+// The returned context carries the new Span, tracer is optional and notified when the Span starts and ends
+func StartSpan(ctx context.Context, tracer Tracer, name string) (context.Context, *Span) {
+	span := &Span{
+		Name:     name,
+		OriginID: OriginFromContext(ctx),
+		Start:    time.Now(),
+		tracer:   tracer,
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+		span.Flags = parent.Flags
+	} else if remote, ok := ctx.Value(remoteParentKey{}).(remoteParent); ok {
+		span.TraceID = remote.traceID
+		span.ParentID = remote.spanID
+		span.Flags = remote.flags
+	} else {
+		randomID(span.TraceID[:])
+		span.Flags = TraceFlagsSampled
+	}
+	randomID(span.SpanID[:])
+	if tracer != nil {
+		tracer.SpanStarted(span)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// readRandom is the source of trace and span ids, replaced in tests
+var readRandom = rand.Read
+
+// randomID fills id with random bytes, falling back to a pseudo random source if crypto/rand fails so the id is never all zeros (which is invalid)
+func randomID(id []byte) {
+	if _, err := readRandom(id); err == nil && !allZero(id) {
+		return
+	}
+	for allZero(id) {
+		for i := 0; i < len(id); i += 8 {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], mathrand.Uint64())
+			copy(id[i:], b[:])
+		}
+	}
+}
+
+func allZero(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SpanFromContext returns the current Span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// WithTraceparent returns a copy of ctx continuing the trace identified by a W3C traceparent header, e.g. from an incoming request
+func WithTraceparent(ctx context.Context, traceparent string) (context.Context, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx, errors.New("invalid traceparent")
+	}
+	var remote remoteParent
+	if _, err := hex.Decode(remote.traceID[:], []byte(parts[1])); err != nil {
+		return ctx, errors.New("invalid traceparent trace-id")
+	}
+	if _, err := hex.Decode(remote.spanID[:], []byte(parts[2])); err != nil {
+		return ctx, errors.New("invalid traceparent parent-id")
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return ctx, errors.New("invalid traceparent trace-flags")
+	}
+	remote.flags = TraceFlags(flags[0])
+	if !remote.traceID.IsValid() || !remote.spanID.IsValid() {
+		return ctx, errors.New("invalid traceparent")
+	}
+	return context.WithValue(ctx, remoteParentKey{}, remote), nil
+}
+
+// WithOrigin returns a copy of ctx carrying the origin used to augment logging and spans for requests made with it
+func WithOrigin(ctx context.Context, originID string) context.Context {
+	return context.WithValue(ctx, originKey{}, originID)
+}
+
+// OriginFromContext returns the origin carried by ctx, or an empty string
+func OriginFromContext(ctx context.Context) string {
+	origin, _ := ctx.Value(originKey{}).(string)
+	return origin
+}
+
+// Traced reports whether ctx is part of a trace, i.e. carries a Span or a traceparent
+func Traced(ctx context.Context) bool {
+	if SpanFromContext(ctx) != nil {
+		return true
+	}
+	_, ok := ctx.Value(remoteParentKey{}).(remoteParent)
+	return ok
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTraceparentFlags(t *testing.T) {
+	for _, flags := range []string{"00", "01"} {
+		ctx, err := WithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-"+flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, parent := StartSpan(ctx, nil, "parent")
+		_, child := StartSpan(ctx, nil, "child")
+		for _, span := range []*Span{parent, child} {
+			if !strings.HasSuffix(span.Traceparent(), "-"+flags) {
+				t.Errorf("expected %s span to carry the trace flags %s but was %s", span.Name, flags, span.Traceparent())
+			}
+		}
+	}
+	if _, err := WithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz"); err == nil {
+		t.Error("expected invalid trace flags to return an error")
+	}
+	// new traces are sampled
+	_, root := StartSpan(context.Background(), nil, "root")
+	if !root.Flags.Sampled() || !strings.HasSuffix(root.Traceparent(), "-01") {
+		t.Errorf("expected a new trace to be sampled but was %s", root.Traceparent())
+	}
+}
+
+func TestSpanIDsWithoutRandom(t *testing.T) {
+	defer func(r func([]byte) (int, error)) { readRandom = r }(readRandom)
+	readRandom = func(b []byte) (int, error) {
+		return 0, errors.New("no randomness")
+	}
+	_, span := StartSpan(context.Background(), nil, "span")
+	if !span.TraceID.IsValid() || !span.SpanID.IsValid() {
+		t.Errorf("expected valid ids when crypto/rand fails, see %s", span.Traceparent())
+	}
+}
@@ -0,0 +1,87 @@
+package justin
+
+import (
+	"net/http"
+	"net/mail"
+	"net/url"
+
+	"github.com/homemade/justin/models"
+)
+
+// Credentials provides an interface for authenticating requests to the user authenticated JustGiving API methods
+//
+// Authorize is called with each API request before it is sent and should decorate it with the required authentication, e.g. an Authorization header
+type Credentials interface {
+	Authorize(req *http.Request) error
+}
+
+// CredentialsFunc provides a type for single function implementations of the Credentials interface
+type CredentialsFunc func(req *http.Request) error
+
+// Authorize defines the single method Credentials interface
+func (f CredentialsFunc) Authorize(req *http.Request) error {
+	return f(req)
+}
+
+// BasicCredentials returns Credentials for the Basic Authentication header expected by JustGiving using the user account's email and password
+func BasicCredentials(account mail.Address, password string) Credentials {
+	var creds CredentialsFunc
+	creds = func(req *http.Request) error {
+		// mail.Address stores email in the format <rob@golang.org>, we don't want the `<` `>`
+		em := account.String()
+		em = em[1 : len(em)-1]
+		req.SetBasicAuth(em, password)
+		return nil
+	}
+	return creds
+}
+
+// BearerCredentials returns Credentials for a Bearer Authentication header using the specified access token
+func BearerCredentials(accessToken string) Credentials {
+	return &Token{AccessToken: accessToken, TokenType: "Bearer"}
+}
+
+// NoCredentials returns Credentials which leave the request unauthenticated, JustGiving will then reject any user authenticated API method
+func NoCredentials() Credentials {
+	var creds CredentialsFunc
+	creds = func(req *http.Request) error {
+		return nil
+	}
+	return creds
+}
+
+// RegisterFundraisingPageForEventWithCredentials registers a fundraising page on the JustGiving website using the supplied Credentials
+func (svc *Service) RegisterFundraisingPageForEventWithCredentials(creds Credentials, page models.FundraisingPageForEvent) (pageURL *url.URL, signOnURL *url.URL, err error) {
+	return svc.registerFundraisingPageForEvent(creds, page)
+}
+
+// EnsureFundraisingPageForEventWithCredentials registers a fundraising page as EnsureFundraisingPageForEvent using the supplied Credentials, owner is
+// the email address of the user account the Credentials authenticate
+func (svc *Service) EnsureFundraisingPageForEventWithCredentials(owner mail.Address, creds Credentials, page models.FundraisingPageForEvent) (*PageRegistration, error) {
+	return svc.ensureFundraisingPageForEvent(owner, creds, page)
+}
+
+// RegisterFundraisingPageForCampaignWithCredentials registers a fundraising page for a charity campaign using the supplied Credentials
+func (svc *Service) RegisterFundraisingPageForCampaignWithCredentials(creds Credentials, page models.FundraisingPageForCampaign) (pageURL *url.URL, signOnURL *url.URL, err error) {
+	return svc.registerFundraisingPageForCampaign(creds, page)
+}
+
+// DonationsForUserWithCredentials returns the donations made by the user account the supplied Credentials authenticate
+func (svc *Service) DonationsForUserWithCredentials(creds Credentials, opts DonationsForUserOptions) ([]models.Donation, error) {
+	return svc.donationsForUser(creds, opts)
+}
+
+// AddOfflineDonationWithCredentials records an offline donation against the specified fundraising page using the supplied Credentials
+func (svc *Service) AddOfflineDonationWithCredentials(creds Credentials, page *FundraisingPageRef, donation models.OfflineDonation) (id uint, err error) {
+	return svc.addOfflineDonation(creds, page, donation)
+}
+
+// OfflineDonationsWithCredentials returns the offline donations recorded against the specified fundraising page using the supplied Credentials
+func (svc *Service) OfflineDonationsWithCredentials(creds Credentials, page *FundraisingPageRef) ([]models.OfflineDonation, error) {
+	return svc.offlineDonations(creds, page)
+}
+
+// DeleteOfflineDonationWithCredentials removes an offline donation from the specified fundraising page using the supplied Credentials
+func (svc *Service) DeleteOfflineDonationWithCredentials(creds Credentials, page *FundraisingPageRef, id uint) error {
+	return svc.deleteOfflineDonation(creds, page, id)
+}
@@ -0,0 +1,42 @@
+package justin
+
+import (
+	"net/http"
+	"net/mail"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	var auth string
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"donations":[],"pagination":{"totalPages":1}}`))
+	}))
+
+	eml, err := mail.ParseAddress("rob@golang.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		creds Credentials
+		auth  string
+	}{
+		{BasicCredentials(*eml, "goph3r"), "Basic cm9iQGdvbGFuZy5vcmc6Z29waDNy"},
+		{BearerCredentials("accesstoken"), "Bearer accesstoken"},
+		{NoCredentials(), ""},
+		{CredentialsFunc(func(req *http.Request) error {
+			req.Header.Set("Authorization", "Custom secret")
+			return nil
+		}), "Custom secret"},
+	}
+	for _, tt := range tests {
+		auth = "unset"
+		if _, err = svc.DonationsForUserWithCredentials(tt.creds, DonationsForUserOptions{}); err != nil {
+			t.Error(err)
+			continue
+		}
+		if auth != tt.auth {
+			t.Errorf("expected Authorization header to be %q but was %q", tt.auth, auth)
+		}
+	}
+}
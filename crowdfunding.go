@@ -0,0 +1,75 @@
+package justin
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+
+	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
+)
+
+// Project returns the specified JustGiving crowdfunding project
+func (svc *Service) Project(shortName string) (*models.Project, error) {
+	var result models.Project
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/crowdfunding/pages/")
+	path.WriteString(url.PathEscape(shortName))
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, _, err := svc.doAndDecode("Project", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return &result, nil
+}
+
+// ProjectTotals returns the current funding totals for the specified JustGiving crowdfunding project, from the project returned by Project
+func (svc *Service) ProjectTotals(shortName string) (models.ProjectTotals, error) {
+	project, err := svc.Project(shortName)
+	if err != nil {
+		return models.ProjectTotals{}, err
+	}
+	if project == nil {
+		return models.ProjectTotals{}, fmt.Errorf("crowdfunding project %s not found", shortName)
+	}
+	return project.ProjectTotals, nil
+}
+
+// ProjectSupporters returns the pledges made by supporters of the specified JustGiving crowdfunding project
+func (svc *Service) ProjectSupporters(shortName string) ([]models.ProjectSupporter, error) {
+
+	results, totalPagination, err := paginatedProjectSupporters(svc, shortName, 0)
+	if err != nil {
+		return nil, err
+	}
+	if totalPagination > 1 {
+		for i := 2; i <= int(totalPagination); i++ {
+			var nextResults []models.ProjectSupporter
+			nextResults, totalPagination, err = paginatedProjectSupporters(svc, shortName, uint(i))
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, nextResults...)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,150 @@
+package justin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/homemade/justin/api"
+)
+
+// ErrInvalidAPIKey is returned when JustGiving rejects the API Key used by a Service
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// ErrNotValidated is reported by Service.Health while the API Key is still being validated in the background
+var ErrNotValidated = errors.New("api key not validated yet")
+
+// Health reports the result of validating the API Key used by a Service.
+//
+// Ready is true when the last validation succeeded, or validation was skipped.
+//
+// LastChecked is when the API Key was last validated and LastValidated is when validation last succeeded.
+//
+// Err is the error from the last validation, ErrInvalidAPIKey if JustGiving rejected the API Key.
+type Health struct {
+	Ready         bool
+	LastChecked   time.Time
+	LastValidated time.Time
+	Err           error
+}
+
+// healthMonitor tracks API Key validation for a Service, the zero value is ready to use
+type healthMonitor struct {
+	mu            sync.RWMutex
+	started       bool
+	lastChecked   time.Time
+	lastValidated time.Time
+	lastErr       error
+	stop          chan struct{}
+}
+
+// Health returns the result of validating the API Key used by this Service
+func (svc *Service) Health() Health {
+	svc.health.mu.RLock()
+	defer svc.health.mu.RUnlock()
+	if svc.SkipValidation && svc.health.lastChecked.IsZero() {
+		return Health{Ready: true}
+	}
+	if svc.health.lastChecked.IsZero() {
+		return Health{Err: ErrNotValidated}
+	}
+	return Health{
+		Ready:         svc.health.lastErr == nil,
+		LastChecked:   svc.health.lastChecked,
+		LastValidated: svc.health.lastValidated,
+		Err:           svc.health.lastErr,
+	}
+}
+
+// Ready reports whether the last validation of the API Key used by this Service succeeded
+func (svc *Service) Ready() bool {
+	return svc.Health().Ready
+}
+
+// Close stops any background validation of the API Key used by this Service
+func (svc *Service) Close() {
+	svc.health.mu.Lock()
+	defer svc.health.mu.Unlock()
+	if svc.health.stop != nil {
+		close(svc.health.stop)
+		svc.health.stop = nil
+	}
+}
+
+// start validates the API Key in the background, immediately if validateNow is set and then every RevalidationInterval
+func (m *healthMonitor) start(svc *Service, validateNow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+	stop := make(chan struct{})
+	m.stop = stop
+	go func() {
+		if validateNow {
+			svc.revalidate()
+		}
+		if svc.RevalidationInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(svc.RevalidationInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				svc.revalidate()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// revalidate validates the API Key and records the result for Health
+func (svc *Service) revalidate() error {
+	err := svc.validateAPIKey()
+	now := time.Now()
+	svc.health.mu.Lock()
+	defer svc.health.mu.Unlock()
+	svc.health.lastChecked = now
+	svc.health.lastErr = err
+	if err == nil {
+		svc.health.lastValidated = now
+	}
+	return err
+}
+
+// validateAPIKey checks JustGiving accepts the API Key by running an AccountAvailabilityCheck
+func (svc *Service) validateAPIKey() error {
+
+	method := "HEAD"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/account/webmaster@justgiving.com")
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, _, err := svc.do("ValidateAPIKey", req, "")
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode == 401 || res.StatusCode == 403 {
+		return ErrInvalidAPIKey
+	}
+	if res.Header.Get("X-Justgiving-Operation") != "AccountApi:AccountAvailabilityCheck" {
+		return fmt.Errorf("invalid response, expected X-Justgiving-Operation response header to be AccountApi:AccountAvailabilityCheck but recieved %s", res.Header.Get("X-Justgiving-Operation"))
+	}
+	if res.StatusCode != 200 && res.StatusCode != 404 {
+		return fmt.Errorf("invalid response %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package justin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackgroundValidation(t *testing.T) {
+	var mu sync.Mutex
+	valid := true
+	release := make(chan struct{})
+	justgiving := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		if !valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Justgiving-Operation", "AccountApi:AccountAvailabilityCheck")
+	}))
+	defer justgiving.Close()
+	target, err := url.Parse(justgiving.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: redirectTransport{target}}
+
+	// Creation does not wait for JustGiving
+	svc, err := createWithAPIKey(APIKeyContext{
+		APIKey: "testkey", Env: Sandbox, BackgroundValidation: true, RevalidationInterval: 20 * time.Millisecond,
+	}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Close()
+	h := svc.Health()
+	if h.Ready || h.Err != ErrNotValidated {
+		t.Errorf("expected Health to report not validated yet but was %#v", h)
+	}
+	close(release)
+
+	waitFor := func(ready bool) Health {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if h := svc.Health(); h.Ready == ready && !h.LastChecked.IsZero() {
+				return h
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for Ready to be %t", ready)
+		return Health{}
+	}
+	h = waitFor(true)
+	if h.Err != nil || h.LastValidated.IsZero() {
+		t.Errorf("expected Health to report validated but was %#v", h)
+	}
+
+	// Revalidation detects the key being revoked
+	mu.Lock()
+	valid = false
+	mu.Unlock()
+	h = waitFor(false)
+	if !errors.Is(h.Err, ErrInvalidAPIKey) || h.LastValidated.IsZero() {
+		t.Errorf("expected Health to report an invalid api key but was %#v", h)
+	}
+
+	// Synchronous validation returns the invalid key error
+	_, err = createWithAPIKey(APIKeyContext{APIKey: "testkey", Env: Sandbox}, client)
+	if !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected createWithAPIKey to return ErrInvalidAPIKey but returned %v", err)
+	}
+}
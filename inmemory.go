@@ -0,0 +1,79 @@
+package justin
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
+)
+
+// InMemoryPerson returns the specified person remembered by in memory pages, or nil if they are not found
+func (svc *Service) InMemoryPerson(id uint) (*models.RememberedPerson, error) {
+	var result models.RememberedPerson
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/remember/")
+	path.WriteString(strconv.FormatUint(uint64(id), 10))
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, _, err := svc.doAndDecode("InMemoryPerson", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return &result, nil
+}
+
+// SearchInMemoryPersons searches the people remembered by existing in memory pages, e.g. by name, so a new page can be linked to an existing tribute
+//
+// Link a page to a result by setting it as the RememberedPerson of the page, only its ID is required.
+func (svc *Service) SearchInMemoryPersons(query string) ([]models.RememberedPerson, error) {
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/remember/search?q=")
+	path.WriteString(url.QueryEscape(query))
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = struct {
+		Results []models.RememberedPerson `json:"results"`
+	}{}
+	res, _, err := svc.doAndDecode("SearchInMemoryPersons", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result.Results, nil
+}
@@ -0,0 +1,25 @@
+package justin
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchInMemoryPersons(t *testing.T) {
+	var query string
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		w.Write([]byte(`{"results": [{"id": 7, "firstName": "Ada", "lastName": "Lovelace", "town": "London", "dateOfBirth": "/Date(-4796755200000+0000)/", "dateOfDeath": null}]}`))
+	}))
+
+	results, err := svc.SearchInMemoryPersons("Ada Lovelace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "Ada Lovelace" {
+		t.Errorf("expected query to be sent, got %q", query)
+	}
+	if len(results) != 1 || results[0].ID != 7 || results[0].Name() != "Ada Lovelace" || results[0].DateOfBirth.Year() != 1817 || !results[0].DateOfDeath.IsZero() {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
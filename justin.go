@@ -3,12 +3,14 @@ package justin
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"net/mail"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/homemade/justin/api"
@@ -51,7 +53,17 @@ type Service struct {
 	BasePath string
 
 	client *http.Client
-	origin string
+	// origin holds the string set by TraceOrigin, it is shared by the copies of the service so is safe to change while requests are running
+	origin *atomic.Value
+	tenant string
+	oauth  *OAuthContext
+	// refreshes serializes the OAuth token refreshes of TokenFor per key
+	refreshes *keyedMutex
+
+	ctx           context.Context
+	retries       int
+	referenceData *referenceDataCache
+	health        *healthMonitor
 }
 
 // APIKeyContext contains settings for creating a justin Service with an API Key.
@@ -59,21 +71,62 @@ type Service struct {
 // HTTPLogger is an optional implementation of the Logger interface, if not provided no logging will be carried out
 //
 // SkipValidation is an optional flag to skip the call to validate the API Key during creation
+//
+// ReferenceDataTTL is an optional duration to cache the JustGiving countries and currencies lists for, if not provided DefaultReferenceDataTTL is used
+//
+// BackgroundValidation is an optional flag to validate the API Key in the background instead of during creation, see Service.Health
+//
+// RevalidationInterval is an optional interval to periodically revalidate the API Key at, if not provided the API Key is only validated once
+//
+// CircuitBreaker is an optional api.CircuitBreaker to fail requests fast while JustGiving is failing, instead of waiting for the Timeout
+//
+// Metrics is an optional implementation of the api.Metrics interface, observing every request made to JustGiving
+//
+// Tracer is an optional implementation of the api.Tracer interface, receiving a span for every request made to JustGiving
+//
+// MaxResponseBytes is an optional limit on the size of JustGiving responses, if not provided DefaultMaxResponseBytes is used (a negative value is unlimited)
+//
+// Cache is an optional api.Cache for the responses of read requests, e.g. FundraisingPageResults and Event, it can be shared by many services
+//
+// LogPasswordBodies is an optional flag to log the bodies of the requests containing user passwords (AccountRegistration and Validate), by default
+// they are left out of the HTTPLogger
 
 type APIKeyContext struct {
-	APIKey         string
-	Env            Env
-	Timeout        time.Duration
-	HTTPLogger     api.Logger
-	SkipValidation bool
+	APIKey               string
+	Env                  Env
+	Timeout              time.Duration
+	HTTPLogger           api.Logger
+	SkipValidation       bool
+	ReferenceDataTTL     time.Duration
+	BackgroundValidation bool
+	RevalidationInterval time.Duration
+	CircuitBreaker       *api.CircuitBreaker
+	Metrics              api.Metrics
+	Tracer               api.Tracer
+	MaxResponseBytes     int64
+	Cache                *api.Cache
+	LogPasswordBodies    bool
 }
 
+// DefaultMaxResponseBytes is the limit on the size of JustGiving responses when APIKeyContext.MaxResponseBytes is not set
+const DefaultMaxResponseBytes = 10 << 20
+
 // CreateWithAPIKey instantiates the Service using an APIKey for authentication
 func CreateWithAPIKey(api APIKeyContext) (svc *Service, err error) {
+	return createWithAPIKey(api, &http.Client{Timeout: api.Timeout})
+}
+
+func createWithAPIKey(api APIKeyContext, client *http.Client) (svc *Service, err error) {
 	// Create service
 	svc = &Service{
 		APIKeyContext: api,
-		client:        &http.Client{Timeout: api.Timeout},
+		client:        client,
+		origin:        &atomic.Value{},
+		referenceData: &referenceDataCache{},
+		health:        &healthMonitor{},
+	}
+	if api.Cache != nil {
+		client.Transport = api.Cache.TransportWithLimit(client.Transport, svc.config().MaxResponseBytes, api.APIKey)
 	}
 	switch api.Env {
 	case Sandbox:
@@ -83,23 +136,56 @@ func CreateWithAPIKey(api APIKeyContext) (svc *Service, err error) {
 	}
 
 	// Check it works
-	if !svc.SkipValidation {
-		eml, err := mail.ParseAddress("webmaster@justgiving.com")
-		if err != nil {
-			return nil, fmt.Errorf("error creating test email to validate api key %v", err)
-		}
-		_, err = svc.AccountAvailabilityCheck(*eml)
-		if err != nil {
-			return nil, fmt.Errorf("error validating api key %v", err)
+	switch {
+	case svc.SkipValidation:
+	case svc.BackgroundValidation:
+		svc.health.start(svc, true)
+		return svc, nil
+	default:
+		if err = svc.revalidate(); err != nil {
+			return nil, fmt.Errorf("error validating api key %w", err)
 		}
 	}
+	if svc.RevalidationInterval > 0 {
+		svc.health.start(svc, false)
+	}
 
 	return svc, nil
 }
 
 // TraceOrigin will augment any logging with the specified origin
+//
+// TraceOrigin changes the origin for every caller of the service (and its copies) and is safe to call while requests are running,
+// to trace the origin of individual requests use api.WithOrigin with WithContext
 func (svc *Service) TraceOrigin(origin string) {
-	svc.origin = origin
+	svc.origin.Store(origin)
+}
+
+// traceOrigin returns the origin set by TraceOrigin, or an empty string
+func (svc *Service) traceOrigin() string {
+	origin, _ := svc.origin.Load().(string)
+	return origin
+}
+
+// WithContext returns a copy of the service which sends its requests with the specified context.Context
+//
+// The context can carry a trace (see api.StartSpan and api.WithTraceparent) and an origin (see api.WithOrigin) for the requests, as well as deadlines and cancellation
+func (svc *Service) WithContext(ctx context.Context) *Service {
+	c := *svc
+	c.ctx = ctx
+	return &c
+}
+
+func (svc *Service) context() context.Context {
+	if svc.ctx == nil {
+		return context.Background()
+	}
+	return svc.ctx
+}
+
+// Tenant returns the tenant this service was created for by a Registry, or an empty string
+func (svc *Service) Tenant() string {
+	return svc.tenant
 }
 
 // AccountAvailabilityCheck checks the availability of a JustGiving account by email address
@@ -122,7 +208,7 @@ func (svc *Service) AccountAvailabilityCheck(account mail.Address) (avail bool,
 		return false, err
 	}
 
-	res, _, err := api.Do(svc.client, svc.origin, "AccountAvailabilityCheck", req, "", svc.HTTPLogger)
+	res, _, err := svc.do("AccountAvailabilityCheck", req, "")
 	if err != nil {
 		return false, err
 	}
@@ -171,7 +257,10 @@ func (svc *Service) Validate(account mail.Address, password string) (valid bool,
 		return false, err
 	}
 
-	res, resBody, err := api.Do(svc.client, svc.origin, "Validate", req, sBody, svc.HTTPLogger)
+	var result = struct {
+		IsValid bool `json:"isValid"`
+	}{}
+	res, _, err := svc.doAndDecodeWithPassword("Validate", req, sBody, &result)
 	if err != nil {
 		return false, err
 	}
@@ -179,16 +268,41 @@ func (svc *Service) Validate(account mail.Address, password string) (valid bool,
 	if res.StatusCode != 200 {
 		return false, fmt.Errorf("invalid response %s", res.Status)
 	}
-	var result = struct {
-		IsValid bool `json:"isValid"`
-	}{}
-	if err := json.Unmarshal([]byte(resBody), &result); err != nil {
-		return false, fmt.Errorf("invalid response %v", err)
-	}
 	return result.IsValid, nil
 
 }
 
+// RegistrationError is returned when JustGiving rejects an account or fundraising page registration
+//
+// Errors are the problems reported by JustGiving mapped onto the fields of the request, use errors.As to retrieve them as models.ValidationErrors.
+type RegistrationError struct {
+	Status     string
+	StatusCode int
+	Errors     models.ValidationErrors
+	// info is the result of running validation on the request payload
+	info string
+}
+
+func newRegistrationError(res *http.Response, info string, errs models.ValidationErrors) *RegistrationError {
+	return &RegistrationError{Status: res.Status, StatusCode: res.StatusCode, Errors: errs, info: info}
+}
+
+func (e *RegistrationError) Error() string {
+	msg := fmt.Sprintf("invalid response %s, result of running validation on request payload was: %s", e.Status, e.info)
+	if len(e.Errors) > 0 {
+		msg += ", JustGiving reported: " + e.Errors.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the Errors reported by JustGiving, if any
+func (e *RegistrationError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors
+}
+
 // AccountRegistration registers a new user account with JustGiving
 func (svc *Service) AccountRegistration(account models.Account) (err error) {
 
@@ -208,7 +322,7 @@ func (svc *Service) AccountRegistration(account models.Account) (err error) {
 		return err
 	}
 
-	res, _, err := api.Do(svc.client, svc.origin, "AccountRegistration", req, sBody, svc.HTTPLogger)
+	res, resBody, err := svc.doWithPassword("AccountRegistration", req, sBody)
 	if err != nil {
 		return err
 	}
@@ -216,7 +330,9 @@ func (svc *Service) AccountRegistration(account models.Account) (err error) {
 	if res.StatusCode != 200 {
 		// run request validation on failure
 		info := "no errors found"
-		valid, err := account.HasValidCountry(svc)
+		vsvc, finish := svc.span("AccountRegistration validation")
+		valid, err := account.HasValidCountry(vsvc)
+		finish(err)
 		if err != nil {
 			info = fmt.Sprintf("errors running validation %v", err)
 		} else {
@@ -224,46 +340,24 @@ func (svc *Service) AccountRegistration(account models.Account) (err error) {
 				info = "invalid Country"
 			}
 		}
-		return fmt.Errorf("invalid response %s, result of running validation on request payload was: %s", res.Status, info)
+		return newRegistrationError(res, info, account.FieldErrors(models.ParseResponseErrors(resBody)))
 	}
 	return nil
 
 }
 
 // IsValidCountry checks the Country used by models.Account is in the published JustGiving countries list
+//
+// Names are matched case-insensitively against the cached list returned by Countries
 func (svc *Service) IsValidCountry(name string) (bool, error) {
 
-	method := "GET"
-
-	path := bytes.NewBuffer([]byte(svc.BasePath))
-	path.WriteString("/")
-	path.WriteString(svc.APIKey)
-	path.WriteString("/v1/countries")
-
-	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
-	if err != nil {
-		return false, err
-	}
-
-	res, resBody, err := api.Do(svc.client, svc.origin, "IsValidCountry", req, "", svc.HTTPLogger)
+	countries, err := svc.countries()
 	if err != nil {
 		return false, err
 	}
 
-	if res.StatusCode != 200 {
-		return false, fmt.Errorf("invalid response %s", res.Status)
-	}
-
-	var result = []struct {
-		Name string `json:"name"`
-	}{}
-
-	if err := json.Unmarshal([]byte(resBody), &result); err != nil {
-		return false, fmt.Errorf("invalid response %v", err)
-	}
-
-	for _, c := range result {
-		if name == c.Name {
+	for _, c := range countries {
+		if strings.EqualFold(name, c.Name) {
 			return true, nil
 		}
 	}
@@ -292,7 +386,7 @@ func (svc *Service) RequestPasswordReminder(account mail.Address) error {
 		return err
 	}
 
-	res, _, err := api.Do(svc.client, svc.origin, "RequestPasswordReminder", req, "", svc.HTTPLogger)
+	res, _, err := svc.do("RequestPasswordReminder", req, "")
 	if err != nil {
 		return err
 	}
@@ -310,58 +404,83 @@ func (svc *Service) FundraisingPageURLCheck(pageShortName string) (avail bool, s
 	// if page is not available we return some suggestions
 	var suggs []string
 
+	avail, err = svc.fundraisingPageShortNameAvailable(pageShortName)
+	if err != nil || avail {
+		return avail, suggs, err
+	}
+	// Page short name already registered
+	// Return a list of suggestions
+	suggs, err = svc.fundraisingPageShortNameSuggestions(pageShortName)
+	return false, suggs, err
+
+}
+
+// fundraisingPageShortNameAvailable checks the availability of a JustGiving fundraising page short name
+func (svc *Service) fundraisingPageShortNameAvailable(pageShortName string) (bool, error) {
+
 	method := "HEAD"
 
 	path := bytes.NewBuffer([]byte(svc.BasePath))
 	path.WriteString("/")
 	path.WriteString(svc.APIKey)
 	path.WriteString("/v1/fundraising/pages/")
-	path.WriteString(pageShortName)
+	path.WriteString(url.PathEscape(pageShortName))
 
 	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
 	if err != nil {
-		return false, suggs, err
+		return false, err
 	}
 
-	res, _, err := api.Do(svc.client, svc.origin, "FundraisingPageURLCheck", req, "", svc.HTTPLogger)
+	res, _, err := svc.do("FundraisingPageURLCheck", req, "")
 	if err != nil {
-		return false, suggs, err
+		return false, err
 	}
 
 	// 404 is success, which is a bit dangerous, so we first make sure we have the correct JustGiving response header
 	if res.Header.Get("X-Justgiving-Operation") != "FundraisingApi:FundraisingPageUrlCheck" {
-		return false, suggs, fmt.Errorf("invalid response, expected X-Justgiving-Operation response header to be FundraisingApi:FundraisingPageUrlCheck but recieved %s", res.Header.Get("X-Justgiving-Operation"))
+		return false, fmt.Errorf("invalid response, expected X-Justgiving-Operation response header to be FundraisingApi:FundraisingPageUrlCheck but recieved %s", res.Header.Get("X-Justgiving-Operation"))
 	}
 	if res.StatusCode == 404 {
-		return true, suggs, nil
+		return true, nil
 	}
 	if res.StatusCode != 200 {
-		return false, suggs, fmt.Errorf("invalid response %s", res.Status)
+		return false, fmt.Errorf("invalid response %s", res.Status)
 	}
 	// 200 - Page short name already registered
-	// Return a list of suggestions
-	path = bytes.NewBuffer([]byte(svc.BasePath))
+	return false, nil
+}
+
+// fundraisingPageShortNameSuggestions returns the JustGiving suggestions of alternatives to a short name which is already registered
+func (svc *Service) fundraisingPageShortNameSuggestions(pageShortName string) ([]string, error) {
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
 	path.WriteString("/")
 	path.WriteString(svc.APIKey)
 	path.WriteString("/v1/fundraising/pages/suggest?preferredName=")
 	path.WriteString(url.QueryEscape(pageShortName))
-	req, err = api.BuildRequest(UserAgent, ContentType, "GET", path.String(), nil)
-	res, resBody, err := api.Do(svc.client, svc.origin, "FundraisingPageURLCheck", req, "", svc.HTTPLogger)
+	req, err := api.BuildRequest(UserAgent, ContentType, "GET", path.String(), nil)
 	if err != nil {
-		return false, suggs, err
+		return nil, err
 	}
 	var result = struct {
 		Names []string
 	}{}
-	if err := json.Unmarshal([]byte(resBody), &result); err != nil {
-		return false, suggs, fmt.Errorf("invalid response %v", err)
+	res, _, err := svc.doAndDecode("FundraisingPageURLCheck", req, "", &result)
+	if err != nil {
+		return nil, err
 	}
-	return false, result.Names, nil
-
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+	return result.Names, nil
 }
 
 // RegisterFundraisingPageForEvent registers a fundraising page on the JustGiving website
 func (svc *Service) RegisterFundraisingPageForEvent(account mail.Address, password string, page models.FundraisingPageForEvent) (pageURL *url.URL, signOnURL *url.URL, err error) {
+	return svc.registerFundraisingPageForEvent(BasicCredentials(account, password), page)
+}
+
+func (svc *Service) registerFundraisingPageForEvent(creds Credentials, page models.FundraisingPageForEvent) (pageURL *url.URL, signOnURL *url.URL, err error) {
 
 	method := "PUT"
 
@@ -370,6 +489,11 @@ func (svc *Service) RegisterFundraisingPageForEvent(account mail.Address, passwo
 	path.WriteString(svc.APIKey)
 	path.WriteString("/v1/fundraising/pages")
 
+	// a page remembering a person is an in memory page
+	if page.RememberedPerson != nil && page.ActivityType == "" {
+		page.ActivityType = models.ActivityTypeInMemory
+	}
+
 	sBody, body, err := api.BuildBody("RegisterFundraisingPageForEvent", page, ContentType)
 	if err != nil {
 		return nil, nil, err
@@ -380,12 +504,18 @@ func (svc *Service) RegisterFundraisingPageForEvent(account mail.Address, passwo
 	}
 
 	// This request requires authentication
-	// mail.Address stores email in the format <rob@golang.org>, we don't want the `<` `>`
-	em := account.String()
-	em = em[1 : len(em)-1]
-	req.SetBasicAuth(em, password)
+	if err = creds.Authorize(req); err != nil {
+		return nil, nil, err
+	}
 
-	res, resBody, err := api.Do(svc.client, svc.origin, "RegisterFundraisingPageForEvent", req, sBody, svc.HTTPLogger)
+	// Read page URL and signon URL from response
+	var result = struct {
+		SignOnURL string `json:"signOnUrl"`
+		Page      struct {
+			URL string `json:"uri"`
+		} `json:"next"`
+	}{}
+	res, resBody, err := svc.doAndDecode("RegisterFundraisingPageForEvent", req, sBody, &result)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -395,7 +525,9 @@ func (svc *Service) RegisterFundraisingPageForEvent(account mail.Address, passwo
 		// run request validation on failure
 		var info string
 		var valid bool
-		valid, err = page.HasValidCurrencyCode(svc)
+		vsvc, finish := svc.span("RegisterFundraisingPageForEvent validation")
+		valid, err = page.HasValidCurrencyCode(vsvc)
+		finish(err)
 		if err != nil {
 			info = fmt.Sprintf("errors running CurrencyCode validation %v; ", err)
 		} else {
@@ -410,19 +542,9 @@ func (svc *Service) RegisterFundraisingPageForEvent(account mail.Address, passwo
 		if info == "" {
 			info = "no errors found"
 		}
-		return nil, nil, fmt.Errorf("invalid response %s, result of running validation on request payload was: %s", res.Status, info)
+		return nil, nil, newRegistrationError(res, info, page.FieldErrors(models.ParseResponseErrors(resBody)))
 	}
 
-	// Read page URL and signon URL from response
-	var result = struct {
-		SignOnURL string `json:"signOnUrl"`
-		Page      struct {
-			URL string `json:"uri"`
-		} `json:"next"`
-	}{}
-	if err = json.Unmarshal([]byte(resBody), &result); err != nil {
-		return nil, nil, fmt.Errorf("invalid response %v", err)
-	}
 	pageURL, err = url.Parse(result.Page.URL)
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid response %v", err)
@@ -437,38 +559,17 @@ func (svc *Service) RegisterFundraisingPageForEvent(account mail.Address, passwo
 }
 
 // IsValidCurrencyCode checks the CurrencyCode used by models.FundraisingPageForEvent is in the published JustGiving currency code list
+//
+// Codes are matched case-insensitively against the cached list returned by Currencies
 func (svc *Service) IsValidCurrencyCode(code string) (bool, error) {
-	method := "GET"
-
-	path := bytes.NewBuffer([]byte(svc.BasePath))
-	path.WriteString("/")
-	path.WriteString(svc.APIKey)
-	path.WriteString("/v1/fundraising/currencies")
-
-	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
-	if err != nil {
-		return false, err
-	}
 
-	res, resBody, err := api.Do(svc.client, svc.origin, "IsValidCurrencyCode", req, "", svc.HTTPLogger)
+	currencies, err := svc.currencies()
 	if err != nil {
 		return false, err
 	}
 
-	if res.StatusCode != 200 {
-		return false, fmt.Errorf("invalid response %s", res.Status)
-	}
-
-	var result = []struct {
-		Code string `json:"currencyCode"`
-	}{}
-
-	if err := json.Unmarshal([]byte(resBody), &result); err != nil {
-		return false, fmt.Errorf("invalid response %v", err)
-	}
-
-	for _, c := range result {
-		if code == c.Code {
+	for _, c := range currencies {
+		if strings.EqualFold(code, c.Code) {
 			return true, nil
 		}
 	}
@@ -476,7 +577,9 @@ func (svc *Service) IsValidCurrencyCode(code string) (bool, error) {
 
 }
 
-// FundraisingPageRef represents a valid reference to a JustGiving fundraising page
+// FundraisingPageRef represents a reference to a JustGiving fundraising page
+//
+// References are returned by the API methods listing pages, or can be created with NewFundraisingPageRef or FundraisingPageRefByID and completed with ResolveFundraisingPageRef.
 type FundraisingPageRef struct {
 	charityID uint
 
@@ -504,19 +607,23 @@ func (r *FundraisingPageRef) ShortName() string {
 func (svc *Service) FundraisingPageResults(page *FundraisingPageRef) (models.FundraisingResults, error) {
 
 	var result models.FundraisingResults
+	if page.shortName == "" {
+		return result, ErrUnresolvedFundraisingPageRef
+	}
+
 	method := "GET"
 	path := bytes.NewBuffer([]byte(svc.BasePath))
 	path.WriteString("/")
 	path.WriteString(svc.APIKey)
 	path.WriteString("/v1/fundraising/pages/")
-	path.WriteString(page.shortName)
+	path.WriteString(url.PathEscape(page.shortName))
 
 	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
 	if err != nil {
 		return result, err
 	}
 
-	res, resBody, err := api.Do(svc.client, svc.origin, "FundraisingPageResults", req, "", svc.HTTPLogger)
+	res, _, err := svc.doAndDecode("FundraisingPageResults", req, "", &result)
 	if err != nil {
 		return result, err
 	}
@@ -530,11 +637,6 @@ func (svc *Service) FundraisingPageResults(page *FundraisingPageRef) (models.Fun
 		return result, fmt.Errorf("invalid response %s", res.Status)
 	}
 
-	result = models.FundraisingResults{}
-	if err = json.Unmarshal([]byte(resBody), &result); err != nil {
-		return result, fmt.Errorf("invalid response %v", err)
-	}
-
 	return result, nil
 
 }
@@ -563,7 +665,12 @@ func (svc *Service) FundraisingPagesForCharityAndUser(charityID uint, account ma
 	if err != nil {
 		return nil, err
 	}
-	res, resBody, err := api.Do(svc.client, svc.origin, "FundraisingPagesForCharityAndUser", req, "", svc.HTTPLogger)
+	var result = []struct {
+		EventID       uint   `json:"eventId"`
+		PageID        uint   `json:"pageId"`
+		PageShortName string `json:"pageShortName"`
+	}{}
+	res, _, err := svc.doAndDecode("FundraisingPagesForCharityAndUser", req, "", &result)
 	if err != nil {
 		return nil, err
 	}
@@ -576,16 +683,6 @@ func (svc *Service) FundraisingPagesForCharityAndUser(charityID uint, account ma
 		return nil, fmt.Errorf("invalid response %s", res.Status)
 	}
 
-	var result = []struct {
-		EventID       uint   `json:"eventId"`
-		PageID        uint   `json:"pageId"`
-		PageShortName string `json:"pageShortName"`
-	}{}
-
-	if err := json.Unmarshal([]byte(resBody), &result); err != nil {
-		return nil, fmt.Errorf("invalid response %v", err)
-	}
-
 	for _, p := range result {
 		if p.PageID > 0 {
 			results = append(results, &FundraisingPageRef{
@@ -602,7 +699,10 @@ func (svc *Service) FundraisingPagesForCharityAndUser(charityID uint, account ma
 }
 
 // FundraisingPagesForEvent returns the fundraising pages registered for the specified event
-func (svc *Service) FundraisingPagesForEvent(eventID uint) ([]*FundraisingPageRef, error) {
+func (svc *Service) FundraisingPagesForEvent(eventID uint) (refs []*FundraisingPageRef, err error) {
+
+	svc, finish := svc.span("FundraisingPagesForEvent")
+	defer func() { finish(err) }()
 
 	results, totalPagination, totalFundraisingPages, err := paginatedFundraisingPagesForEvent(svc, eventID, 0)
 	if err != nil {
@@ -644,7 +744,7 @@ func (svc *Service) Event(eventID uint) (*models.Event, error) {
 		return nil, err
 	}
 
-	res, resBody, err := api.Do(svc.client, svc.origin, "Event", req, "", svc.HTTPLogger)
+	res, _, err := svc.doAndDecode("Event", req, "", &result)
 	if err != nil {
 		return nil, err
 	}
@@ -657,9 +757,177 @@ func (svc *Service) Event(eventID uint) (*models.Event, error) {
 		return nil, fmt.Errorf("invalid response %s", res.Status)
 	}
 
-	if err = json.Unmarshal([]byte(resBody), &result); err != nil {
-		return nil, fmt.Errorf("invalid response %v", err)
+	return &result, nil
+}
+
+// DonationsForUserOptions contains optional settings for DonationsForUser.
+//
+// CharityID is an optional filter to only return donations made to the specified charity.
+//
+// PageSize is an optional number of donations to request per page, if not provided a page size of 100 is used.
+type DonationsForUserOptions struct {
+	CharityID uint
+	PageSize  uint
+}
+
+// DonationsForUser returns the donations made by the specified JustGiving user account
+func (svc *Service) DonationsForUser(account mail.Address, password string, opts DonationsForUserOptions) ([]models.Donation, error) {
+	return svc.donationsForUser(BasicCredentials(account, password), opts)
+}
+
+func (svc *Service) donationsForUser(creds Credentials, opts DonationsForUserOptions) ([]models.Donation, error) {
+
+	results, totalPagination, err := paginatedDonationsForUser(svc, creds, opts, 0)
+	if err != nil {
+		return nil, err
+	}
+	if totalPagination > 1 {
+		for i := 2; i <= int(totalPagination); i++ {
+			var nextResults []models.Donation
+			nextResults, totalPagination, err = paginatedDonationsForUser(svc, creds, opts, uint(i))
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, nextResults...)
+		}
+	}
+
+	return results, nil
+}
+
+// Campaign returns the specified JustGiving charity campaign
+func (svc *Service) Campaign(charityShortName string, campaignShortName string) (*models.Campaign, error) {
+	var result models.Campaign
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/campaigns/")
+	path.WriteString(url.PathEscape(charityShortName))
+	path.WriteString("/")
+	path.WriteString(url.PathEscape(campaignShortName))
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, _, err := svc.doAndDecode("Campaign", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
 	}
 
 	return &result, nil
 }
+
+// FundraisingPagesForCampaign returns the fundraising pages registered for the specified charity campaign
+func (svc *Service) FundraisingPagesForCampaign(charityShortName string, campaignShortName string) ([]*FundraisingPageRef, error) {
+
+	results, totalPagination, totalFundraisingPages, err := paginatedFundraisingPagesForCampaign(svc, charityShortName, campaignShortName, 0)
+	if err != nil {
+		return nil, err
+	}
+	if totalPagination > 1 {
+		for i := 2; i <= int(totalPagination); i++ {
+			var nextResults []*FundraisingPageRef
+			nextResults, totalPagination, totalFundraisingPages, err = paginatedFundraisingPagesForCampaign(svc, charityShortName, campaignShortName, uint(i))
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, nextResults...)
+		}
+	}
+
+	if int(totalFundraisingPages) != len(results) {
+		return results, fmt.Errorf("inconsistent read, expected %d results but have %d", int(totalFundraisingPages), len(results))
+	}
+
+	return results, nil
+}
+
+// RegisterFundraisingPageForCampaign registers a fundraising page for a charity campaign on the JustGiving website
+func (svc *Service) RegisterFundraisingPageForCampaign(account mail.Address, password string, page models.FundraisingPageForCampaign) (pageURL *url.URL, signOnURL *url.URL, err error) {
+	return svc.registerFundraisingPageForCampaign(BasicCredentials(account, password), page)
+}
+
+func (svc *Service) registerFundraisingPageForCampaign(creds Credentials, page models.FundraisingPageForCampaign) (pageURL *url.URL, signOnURL *url.URL, err error) {
+
+	method := "PUT"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/campaigns")
+
+	sBody, body, err := api.BuildBody("RegisterFundraisingPageForCampaign", page, ContentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// This request requires authentication
+	if err = creds.Authorize(req); err != nil {
+		return nil, nil, err
+	}
+
+	// Read page URL and signon URL from response
+	var result = struct {
+		SignOnURL string `json:"signOnUrl"`
+		Page      struct {
+			URL string `json:"uri"`
+		} `json:"next"`
+	}{}
+	res, resBody, err := svc.doAndDecode("RegisterFundraisingPageForCampaign", req, sBody, &result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//201 Created
+	if res.StatusCode != 201 {
+		// run request validation on failure
+		var info string
+		var valid bool
+		vsvc, finish := svc.span("RegisterFundraisingPageForCampaign validation")
+		valid, err = page.HasValidCurrencyCode(vsvc)
+		finish(err)
+		if err != nil {
+			info = fmt.Sprintf("errors running CurrencyCode validation %v; ", err)
+		} else {
+			if !valid {
+				info = "invalid CurrencyCode; "
+			}
+		}
+		valid = page.HasValidTargetAmount()
+		if !valid {
+			info += "invalid TargetAmount"
+		}
+		if info == "" {
+			info = "no errors found"
+		}
+		return nil, nil, newRegistrationError(res, info, page.FieldErrors(models.ParseResponseErrors(resBody)))
+	}
+
+	pageURL, err = url.Parse(result.Page.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid response %v", err)
+	}
+	signOnURL, err = url.Parse(result.SignOnURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid response %v", err)
+	}
+
+	return pageURL, signOnURL, nil
+
+}
@@ -2,11 +2,14 @@ package justin
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"sync"
 
 	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
 )
 
 func paginatedFundraisingPagesForEvent(svc *Service, eventID uint, pagination uint) (results []*FundraisingPageRef, totalPagination uint, totalFundraisingPages uint, err error) {
@@ -25,11 +28,25 @@ func paginatedFundraisingPagesForEvent(svc *Service, eventID uint, pagination ui
 		pg = strconv.FormatUint(uint64(pagination), 10)
 	}
 
+	// trace each page separately
+	svc, finish := svc.span("FundraisingPagesForEvent page " + pg)
+	defer func() { finish(err) }()
+
 	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String()+"&page="+pg, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
-	res, resBody, err := api.Do(svc.client, svc.origin, "FundraisingPagesForEvent", req, "", svc.HTTPLogger)
+	type page struct {
+		CharityID     uint   `json:"charityId"`
+		PageID        uint   `json:"pageId"`
+		PageShortName string `json:"pageShortName"`
+	}
+	var result = struct {
+		TotalPagination       uint   `json:"totalPages"`
+		TotalFundraisingPages uint   `json:"totalFundraisingPages"`
+		FundraisingPages      []page `json:"fundraisingPages"`
+	}{}
+	res, _, err := svc.doAndDecode("FundraisingPagesForEvent", req, "", &result)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -37,8 +54,98 @@ func paginatedFundraisingPagesForEvent(svc *Service, eventID uint, pagination ui
 	if res.StatusCode != 200 {
 		return nil, 0, 0, fmt.Errorf("invalid response %s", res.Status)
 	}
+
+	for _, p := range result.FundraisingPages {
+		if p.PageID > 0 {
+			results = append(results, &FundraisingPageRef{
+				charityID: p.CharityID,
+				eventID:   eventID,
+				id:        p.PageID,
+				shortName: p.PageShortName,
+			})
+
+		}
+	}
+
+	return results, result.TotalPagination, result.TotalFundraisingPages, nil
+}
+
+func paginatedDonationsForUser(svc *Service, creds Credentials, opts DonationsForUserOptions, pagination uint) (results []models.Donation, totalPagination uint, err error) {
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/account/donations?pageSize=")
+	if opts.PageSize > 0 {
+		path.WriteString(strconv.FormatUint(uint64(opts.PageSize), 10))
+	} else {
+		path.WriteString("100")
+	}
+	if opts.CharityID > 0 {
+		path.WriteString("&charityId=")
+		path.WriteString(strconv.FormatUint(uint64(opts.CharityID), 10))
+	}
+
+	// set pagination
+	pg := "1"
+	if pagination > 0 {
+		pg = strconv.FormatUint(uint64(pagination), 10)
+	}
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String()+"&pageNum="+pg, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This request requires authentication
+	if err = creds.Authorize(req); err != nil {
+		return nil, 0, err
+	}
+
+	var result = struct {
+		Donations  []models.Donation `json:"donations"`
+		Pagination struct {
+			TotalPagination uint `json:"totalPages"`
+		} `json:"pagination"`
+	}{}
+	res, _, err := svc.doAndDecode("DonationsForUser", req, "", &result)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if res.StatusCode != 200 {
+		return nil, 0, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result.Donations, result.Pagination.TotalPagination, nil
+}
+
+func paginatedFundraisingPagesForCampaign(svc *Service, charityShortName string, campaignShortName string, pagination uint) (results []*FundraisingPageRef, totalPagination uint, totalFundraisingPages uint, err error) {
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/campaigns/")
+	path.WriteString(url.PathEscape(charityShortName))
+	path.WriteString("/")
+	path.WriteString(url.PathEscape(campaignShortName))
+	path.WriteString("/pages?pageSize=100")
+
+	// set pagination
+	pg := "1"
+	if pagination > 0 {
+		pg = strconv.FormatUint(uint64(pagination), 10)
+	}
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String()+"&page="+pg, nil)
+	if err != nil {
+		return nil, 0, 0, err
+	}
 	type page struct {
 		CharityID     uint   `json:"charityId"`
+		EventID       uint   `json:"eventId"`
 		PageID        uint   `json:"pageId"`
 		PageShortName string `json:"pageShortName"`
 	}
@@ -47,16 +154,20 @@ func paginatedFundraisingPagesForEvent(svc *Service, eventID uint, pagination ui
 		TotalFundraisingPages uint   `json:"totalFundraisingPages"`
 		FundraisingPages      []page `json:"fundraisingPages"`
 	}{}
+	res, _, err := svc.doAndDecode("FundraisingPagesForCampaign", req, "", &result)
+	if err != nil {
+		return nil, 0, 0, err
+	}
 
-	if err := json.Unmarshal([]byte(resBody), &result); err != nil {
-		return nil, 0, 0, fmt.Errorf("invalid response %v", err)
+	if res.StatusCode != 200 {
+		return nil, 0, 0, fmt.Errorf("invalid response %s", res.Status)
 	}
 
 	for _, p := range result.FundraisingPages {
 		if p.PageID > 0 {
 			results = append(results, &FundraisingPageRef{
 				charityID: p.CharityID,
-				eventID:   eventID,
+				eventID:   p.EventID,
 				id:        p.PageID,
 				shortName: p.PageShortName,
 			})
@@ -66,3 +177,129 @@ func paginatedFundraisingPagesForEvent(svc *Service, eventID uint, pagination ui
 
 	return results, result.TotalPagination, result.TotalFundraisingPages, nil
 }
+
+func paginatedProjectSupporters(svc *Service, shortName string, pagination uint) (results []models.ProjectSupporter, totalPagination uint, err error) {
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/crowdfunding/pages/")
+	path.WriteString(url.PathEscape(shortName))
+	path.WriteString("/pledges?pageSize=100")
+
+	// set pagination
+	pg := "1"
+	if pagination > 0 {
+		pg = strconv.FormatUint(uint64(pagination), 10)
+	}
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String()+"&pageNum="+pg, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	var result = struct {
+		Pledges    []models.ProjectSupporter `json:"pledges"`
+		Pagination struct {
+			TotalPagination uint `json:"totalPages"`
+		} `json:"pagination"`
+	}{}
+	res, _, err := svc.doAndDecode("ProjectSupporters", req, "", &result)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if res.StatusCode == 404 {
+		return results, 0, nil
+	}
+
+	if res.StatusCode != 200 {
+		return nil, 0, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result.Pledges, result.Pagination.TotalPagination, nil
+}
+
+// do transports a single API request using the settings and context of the service
+func (svc *Service) do(calleeID string, req *http.Request, reqBody string) (*http.Response, string, error) {
+	return api.DoWithConfig(svc.client, svc.config(), calleeID, req.WithContext(svc.context()), reqBody)
+}
+
+// doAndDecode transports a single API request using the settings and context of the service, decoding a successful JSON response into v
+func (svc *Service) doAndDecode(calleeID string, req *http.Request, reqBody string, v interface{}) (*http.Response, string, error) {
+	return api.DoAndDecode(svc.client, svc.config(), calleeID, req.WithContext(svc.context()), reqBody, v)
+}
+
+// doAndDecodeSensitive is doAndDecode for a request or response carrying credentials, which are kept out of the logs
+func (svc *Service) doAndDecodeSensitive(calleeID string, req *http.Request, reqBody string, v interface{}) (*http.Response, string, error) {
+	config := svc.config()
+	config.Sensitive = true
+	return api.DoAndDecode(svc.client, config, calleeID, req.WithContext(svc.context()), reqBody, v)
+}
+
+// doWithPassword is do for a request with a user password in its body, the bodies are kept out of the logs unless LogPasswordBodies is set
+func (svc *Service) doWithPassword(calleeID string, req *http.Request, reqBody string) (*http.Response, string, error) {
+	config := svc.config()
+	config.Sensitive = !svc.LogPasswordBodies
+	return api.DoWithConfig(svc.client, config, calleeID, req.WithContext(svc.context()), reqBody)
+}
+
+// doAndDecodeWithPassword is doAndDecode for a request with a user password in its body, the bodies are kept out of the logs unless LogPasswordBodies is set
+func (svc *Service) doAndDecodeWithPassword(calleeID string, req *http.Request, reqBody string, v interface{}) (*http.Response, string, error) {
+	config := svc.config()
+	config.Sensitive = !svc.LogPasswordBodies
+	return api.DoAndDecode(svc.client, config, calleeID, req.WithContext(svc.context()), reqBody, v)
+}
+
+func (svc *Service) config() api.Config {
+	maxResponseBytes := svc.MaxResponseBytes
+	if maxResponseBytes == 0 {
+		maxResponseBytes = DefaultMaxResponseBytes
+	} else if maxResponseBytes < 0 {
+		maxResponseBytes = 0
+	}
+	return api.Config{
+		OriginID:         svc.traceOrigin(),
+		Logger:           svc.HTTPLogger,
+		Redact:           []string{svc.APIKey},
+		MaxResponseBytes: maxResponseBytes,
+		Breaker:          svc.CircuitBreaker,
+		Metrics:          svc.Metrics,
+		Tracer:           svc.Tracer,
+		Retries:          svc.retries,
+	}
+}
+
+// withRetries returns a copy of the service reporting its requests as retried the specified number of times
+func (svc *Service) withRetries(retries int) *Service {
+	c := *svc
+	c.retries = retries
+	return &c
+}
+
+// span starts a child span of the service context when tracing, returning a copy of the service using the span's context and a func to finish the span
+func (svc *Service) span(name string) (*Service, func(err error)) {
+	if svc.Tracer == nil && !api.Traced(svc.context()) {
+		return svc, func(error) {}
+	}
+	ctx, span := api.StartSpan(svc.context(), svc.Tracer, name)
+	return svc.WithContext(ctx), span.Finish
+}
+
+// concurrently calls f with each index up to n, with at most concurrency calls at once, returning when they have all finished
+func concurrently(n int, concurrency int, f func(i int)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
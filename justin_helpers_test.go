@@ -0,0 +1,26 @@
+package justin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestService returns a service sending its requests to a test server running handler, the server is closed when the test finishes
+//
+// The service is created without validating its API key, opts can change the rest of its APIKeyContext e.g. to set an HTTPLogger.
+func newTestService(t *testing.T, handler http.Handler, opts ...func(*APIKeyContext)) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	ctx := APIKeyContext{APIKey: "testkey", SkipValidation: true}
+	for _, opt := range opts {
+		opt(&ctx)
+	}
+	svc, err := CreateWithAPIKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.BasePath = server.URL
+	return svc
+}
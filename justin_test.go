@@ -18,10 +18,12 @@ import (
 )
 
 const (
-	APIKeyEnvVar  = "JUSTIN_APIKEY"
-	UserEnvVar    = "JUSTIN_USER"
-	CharityEnvVar = "JUSTIN_CHARITY"
-	EventEnvVar   = "JUSTIN_EVENT"
+	APIKeyEnvVar   = "JUSTIN_APIKEY"
+	UserEnvVar     = "JUSTIN_USER"
+	CharityEnvVar  = "JUSTIN_CHARITY"
+	EventEnvVar    = "JUSTIN_EVENT"
+	CampaignEnvVar = "JUSTIN_CAMPAIGN"
+	ProjectEnvVar  = "JUSTIN_PROJECT"
 )
 
 var (
@@ -93,6 +95,15 @@ func getUserCreds(t *testing.T) (username string, pwd string, err error) {
 	return parts[0], parts[1], nil
 }
 
+func getCampaign(t *testing.T) (charityShortName string, campaignShortName string, err error) {
+	campEV := ev(CampaignEnvVar, t)
+	parts := strings.Split(campEV, ":")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return charityShortName, campaignShortName, fmt.Errorf("invalid or missing env var %s", CampaignEnvVar)
+	}
+	return parts[0], parts[1], nil
+}
+
 func getPage(pageURL url.URL) (string, error) {
 	res, err := http.Get(pageURL.String())
 	if err != nil {
@@ -219,7 +230,7 @@ func testAccountRegistration(t *testing.T, s *Service) bool {
 		t.Error("expected AccountRegistration to return error due to invalid Country")
 		return false
 	}
-	if err.Error() != "invalid response 400 Bad request, result of running validation on request payload was: invalid Country" {
+	if !strings.HasPrefix(err.Error(), "invalid response 400 Bad request, result of running validation on request payload was: invalid Country") {
 		t.Errorf("expected AccountRegistration to return error due to invalid Country but recieved error %v", err)
 		return false
 	}
@@ -280,9 +291,7 @@ func testFundraisingPageAPI(t *testing.T, s *Service) {
 		return
 	}
 	imgs[1] = models.Image{Caption: "Image 2 Caption", URL: *url}
-	var cuscodes [6]string
-	cuscodes[0] = "CUSTOMCODE1"
-	cuscodes[5] = "CUSTOMCODE6"
+	cuscodes := models.CustomCodes{1: "CUSTOMCODE1", 6: "CUSTOMCODE6"}
 	pg := models.FundraisingPageForEvent{
 		CharityID:       uint(charityID),
 		EventID:         uint(eventID),
@@ -417,3 +426,247 @@ func TestFundraisingPageAPI(t *testing.T) {
 	testFundraisingPageAPI(t, s)
 
 }
+
+func testDonationsForUser(t *testing.T, s *Service) {
+	userEmail, pwd, err := getUserCreds(t)
+	if e(t, err) {
+		return
+	}
+	eml, err := mail.ParseAddress(userEmail)
+	if e(t, err) {
+		return
+	}
+	donations, err := s.DonationsForUser(*eml, pwd, DonationsForUserOptions{})
+	if e(t, err) {
+		return
+	}
+	for _, d := range donations {
+		if _, err = d.ParseDonationDate(); e(t, err) {
+			return
+		}
+	}
+	// Filtered by charity
+	charityID, err := strconv.Atoi(ev(CharityEnvVar, t))
+	if e(t, err) {
+		return
+	}
+	filtered, err := s.DonationsForUser(*eml, pwd, DonationsForUserOptions{CharityID: uint(charityID)})
+	if e(t, err) {
+		return
+	}
+	for _, d := range filtered {
+		if d.CharityID != uint(charityID) {
+			t.Errorf("expected DonationsForUser to only return donations for charity %d but returned %#v", charityID, d)
+			return
+		}
+	}
+}
+
+func TestDonationsForUser(t *testing.T) {
+	// Sandbox test
+	s := createService(t, Sandbox)
+	testDonationsForUser(t, s)
+}
+
+func testCampaignAPI(t *testing.T, s *Service) {
+	userEmail, pwd, err := getUserCreds(t)
+	if e(t, err) {
+		return
+	}
+	eml, err := mail.ParseAddress(userEmail)
+	if e(t, err) {
+		return
+	}
+	charityShortName, campaignShortName, err := getCampaign(t)
+	if e(t, err) {
+		return
+	}
+	// Check the campaign
+	camp, err := s.Campaign(charityShortName, campaignShortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if camp == nil {
+		t.Fatalf("expected Campaign to return campaign %s/%s", charityShortName, campaignShortName)
+	}
+	// Create a page
+	pgsn := "testcampaignpage" + time.Now().Format("20060102150405")
+	pg := models.FundraisingPageForCampaign{
+		CampaignID:    camp.ID,
+		CharityID:     camp.CharityID,
+		PageShortName: pgsn,
+		PageTitle:     "Page Title For " + pgsn,
+		PageStory:     "Page Story For " + pgsn,
+		TargetAmount:  "100.00",
+		CurrencyCode:  "GBP",
+	}
+	pageURL, _, err := s.RegisterFundraisingPageForCampaign(*eml, pwd, pg)
+	if e(t, err) {
+		return
+	}
+	html, err := getPage(*pageURL)
+	if e(t, err) {
+		return
+	}
+	if !inPage(html, pg.PageTitle, pg.PageStory) {
+		t.Errorf("the created campaign fundraising page (checked with returned page url) does not look as expected")
+		return
+	}
+	// Check we can retrieve all the pages
+	pages, err := s.FundraisingPagesForCampaign(charityShortName, campaignShortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, p := range pages {
+		if p.ShortName() == pgsn {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected FundraisingPagesForCampaign to include the created page %s", pgsn)
+	}
+}
+
+func TestCampaignAPI(t *testing.T) {
+	// Sandbox test
+	s := createService(t, Sandbox)
+	testCampaignAPI(t, s)
+}
+
+func testCrowdfundingAPI(t *testing.T, s *Service) {
+	shortName := ev(ProjectEnvVar, t)
+	proj, err := s.Project(shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proj == nil {
+		t.Fatalf("expected Project to return project %s", shortName)
+	}
+	if proj.ShortName != shortName {
+		t.Errorf("expected Project to return project %s but returned %#v", shortName, proj)
+		return
+	}
+	totals, err := s.ProjectTotals(shortName)
+	if e(t, err) {
+		return
+	}
+	supporters, err := s.ProjectSupporters(shortName)
+	if e(t, err) {
+		return
+	}
+	if uint(len(supporters)) < totals.NumberOfSupporters {
+		t.Errorf("expected ProjectSupporters to return at least %d supporters but returned %d", totals.NumberOfSupporters, len(supporters))
+	}
+}
+
+func TestCrowdfundingAPI(t *testing.T) {
+	// Sandbox test
+	s := createService(t, Sandbox)
+	testCrowdfundingAPI(t, s)
+}
+
+func testReferenceData(t *testing.T, s *Service) {
+	countries, err := s.Countries()
+	if e(t, err) {
+		return
+	}
+	if len(countries) < 1 {
+		t.Error("expected Countries to return some countries")
+		return
+	}
+	currencies, err := s.Currencies()
+	if e(t, err) {
+		return
+	}
+	if len(currencies) < 1 {
+		t.Error("expected Currencies to return some currencies")
+		return
+	}
+	// Names and codes are matched case-insensitively
+	valid, err := s.IsValidCountry("united kingdom")
+	if e(t, err) {
+		return
+	}
+	if !valid {
+		t.Errorf("expected IsValidCountry to return true but returned %t", valid)
+	}
+	valid, err = s.IsValidCurrencyCode("gbp")
+	if e(t, err) {
+		return
+	}
+	if !valid {
+		t.Errorf("expected IsValidCurrencyCode to return true but returned %t", valid)
+	}
+	valid, err = s.IsValidCountry("Nowhere")
+	if e(t, err) {
+		return
+	}
+	if valid {
+		t.Errorf("expected IsValidCountry to return false but returned %t", valid)
+	}
+}
+
+func TestReferenceData(t *testing.T) {
+	// Sandbox test
+	s := createService(t, Sandbox)
+	testReferenceData(t, s)
+}
+
+func testOfflineDonations(t *testing.T, s *Service) {
+	userEmail, pwd, err := getUserCreds(t)
+	if e(t, err) {
+		return
+	}
+	eml, err := mail.ParseAddress(userEmail)
+	if e(t, err) {
+		return
+	}
+	charityID, err := strconv.Atoi(ev(CharityEnvVar, t))
+	if e(t, err) {
+		return
+	}
+	pages, err := s.FundraisingPagesForCharityAndUser(uint(charityID), *eml)
+	if e(t, err) {
+		return
+	}
+	if len(pages) < 1 {
+		t.Skip("no fundraising pages registered for user to record offline donations against")
+	}
+	page := pages[len(pages)-1]
+	// Invalid amount - expect to fail before sending
+	_, err = s.AddOfflineDonation(*eml, pwd, page, models.OfflineDonation{Amount: "NaN", CurrencyCode: "GBP"})
+	if err == nil || err.Error() != "invalid Amount" {
+		t.Errorf("expected AddOfflineDonation to return error due to invalid Amount but recieved error %v", err)
+		return
+	}
+	// Valid
+	id, err := s.AddOfflineDonation(*eml, pwd, page, models.OfflineDonation{Amount: "10.50", CurrencyCode: "GBP", DonorName: "John Smith"})
+	if e(t, err) {
+		return
+	}
+	donations, err := s.OfflineDonations(*eml, pwd, page)
+	if e(t, err) {
+		return
+	}
+	found := false
+	for _, d := range donations {
+		if d.ID == id {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected OfflineDonations to include the added donation %d", id)
+		return
+	}
+	err = s.DeleteOfflineDonation(*eml, pwd, page, id)
+	if e(t, err) {
+		return
+	}
+}
+
+func TestOfflineDonations(t *testing.T) {
+	// Sandbox test
+	s := createService(t, Sandbox)
+	testOfflineDonations(t, s)
+}
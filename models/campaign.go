@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Campaign represents a JustGiving charity campaign
+type Campaign struct {
+	ID               string `json:"campaignGuid"`
+	ShortName        string `json:"campaignPageName"`
+	Name             string `json:"campaignName"`
+	Summary          string `json:"summary"`
+	Story            string `json:"story"`
+	CharityID        uint   `json:"charityId"`
+	CharityShortName string `json:"charityShortName"`
+	EventID          uint   `json:"eventId"`
+	CurrencyCode     string `json:"currencyCode"`
+	Target           string `json:"fundraisingTarget"`
+	TotalRaised      string `json:"totalRaised"`
+	StartDate        string `json:"startDate"`
+	EndDate          string `json:"endDate"`
+}
+
+// ParseStartDate attempts to convert the StartDate returned by JustGiving to a Time
+func (c Campaign) ParseStartDate() (time.Time, error) {
+	return ParseDate(c.StartDate)
+}
+
+// ParseEndDate attempts to convert the EndDate returned by JustGiving to a Time
+func (c Campaign) ParseEndDate() (time.Time, error) {
+	return ParseDate(c.EndDate)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// MaxCustomCodes is the number of custom codes a fundraising page can have
+const MaxCustomCodes = 6
+
+// CustomCodes are the custom codes of a fundraising page keyed by number, 1 to 6
+//
+// Only the codes set are sent to JustGiving, so codes which aren't set are left unchanged. Set a code to "" to clear it.
+type CustomCodes map[int]string
+
+// MarshalJSON encodes the custom codes as expected by JustGiving, e.g. {"customCode1": "ABC"}, returning an error for a number outside 1 to 6
+func (cc CustomCodes) MarshalJSON() ([]byte, error) {
+	codes := make(map[string]string, len(cc))
+	for n, code := range cc {
+		if n < 1 || n > MaxCustomCodes {
+			return nil, fmt.Errorf("invalid custom code number %d, must be 1 to %d", n, MaxCustomCodes)
+		}
+		codes["customCode"+strconv.Itoa(n)] = code
+	}
+	return json.Marshal(codes)
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JGDate is a date returned by JustGiving, usually in the Microsoft JSON date format e.g. `/Date(1474675200000+0000)/`
+//
+// The number is the milliseconds since the Unix epoch (negative before 1970), the optional offset (e.g. +0100 or -0500)
+// is the timezone of the Time. ISO-8601 dates (e.g. "2016-09-24T00:00:00Z" or "2016-09-24") are also accepted, without a timezone they are UTC.
+// A null or empty value is the zero Time.
+type JGDate struct {
+	time.Time
+}
+
+var isoDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02",
+}
+
+// parseJGDate converts a non empty date returned by JustGiving to a Time
+func parseJGDate(date string) (time.Time, error) {
+	s := strings.TrimSpace(date)
+	if strings.HasPrefix(s, "/Date(") && strings.HasSuffix(s, ")/") {
+		return parseMicrosoftDate(s[len("/Date(") : len(s)-len(")/")])
+	}
+	for _, layout := range isoDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date format %q", date)
+}
+
+// parseMicrosoftDate converts the content of a Microsoft JSON date, milliseconds with an optional offset e.g. `1474675200000-0500`
+func parseMicrosoftDate(s string) (time.Time, error) {
+	ms, offset := s, ""
+	// the milliseconds may be negative, so look for the offset sign after the first character
+	if len(s) > 1 {
+		if i := strings.IndexAny(s[1:], "+-"); i >= 0 {
+			ms, offset = s[:i+1], s[i+1:]
+		}
+	}
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date milliseconds %q", ms)
+	}
+	t := time.Unix(n/1000, (n%1000)*int64(time.Millisecond)).UTC()
+	if offset == "" {
+		return t, nil
+	}
+	if len(offset) != 5 {
+		return time.Time{}, fmt.Errorf("invalid date offset %q", offset)
+	}
+	hours, err := strconv.ParseUint(offset[1:3], 10, 8)
+	if err != nil || hours > 23 {
+		return time.Time{}, fmt.Errorf("invalid date offset %q", offset)
+	}
+	minutes, err := strconv.ParseUint(offset[3:], 10, 8)
+	if err != nil || minutes > 59 {
+		return time.Time{}, fmt.Errorf("invalid date offset %q", offset)
+	}
+	seconds := int(hours*3600 + minutes*60)
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return t.In(time.FixedZone("", seconds)), nil
+}
+
+// MarshalJSON encodes the date in the Microsoft JSON date format, with the offset of its timezone, or null if it is zero
+func (d JGDate) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	_, offset := d.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	s := fmt.Sprintf("/Date(%d%c%02d%02d)/", d.UnixMilli(), sign, offset/3600, offset%3600/60)
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON decodes a date returned by JustGiving
+func (d *JGDate) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		d.Time = time.Time{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid date %s", b)
+	}
+	if strings.TrimSpace(s) == "" {
+		d.Time = time.Time{}
+		return nil
+	}
+	t, err := parseJGDate(s)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// parse returns the Time of the date, or an error naming the field if it is not set
+func (d JGDate) parse(name string) (time.Time, error) {
+	if d.IsZero() {
+		return d.Time, fmt.Errorf("no value set for %s", name)
+	}
+	return d.Time, nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJGDate(t *testing.T) {
+	for _, tc := range []struct {
+		json     string
+		expected time.Time
+		offset   int
+		valid    bool
+	}{
+		{`"/Date(1474675200000+0000)/"`, time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC), 0, true},
+		{`"/Date(1474675200000)/"`, time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC), 0, true},
+		{`"/Date(1474675200123-0500)/"`, time.Date(2016, 9, 24, 0, 0, 0, 123000000, time.UTC), -5 * 3600, true},
+		{`"/Date(1474675200000+0530)/"`, time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC), 5*3600 + 30*60, true},
+		{`"\/Date(-86400000+0100)\/"`, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), 3600, true},
+		{`"/Date(-1)/"`, time.Date(1969, 12, 31, 23, 59, 59, 999000000, time.UTC), 0, true},
+		{`"2016-09-24T01:00:00+01:00"`, time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC), 3600, true},
+		{`"2016-09-24T00:00:00.5"`, time.Date(2016, 9, 24, 0, 0, 0, 500000000, time.UTC), 0, true},
+		{`"2016-09-24"`, time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC), 0, true},
+		{`null`, time.Time{}, 0, true},
+		{`""`, time.Time{}, 0, true},
+		{`"/Date(abc)/"`, time.Time{}, 0, false},
+		{`"/Date(1474675200000+05)/"`, time.Time{}, 0, false},
+		{`"/Date(1474675200000+2500)/"`, time.Time{}, 0, false},
+		{`"24/09/2016"`, time.Time{}, 0, false},
+		{`1474675200000`, time.Time{}, 0, false},
+	} {
+		var d JGDate
+		err := json.Unmarshal([]byte(tc.json), &d)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s, unexpected error %v", tc.json, err)
+			continue
+		}
+		if !tc.valid {
+			continue
+		}
+		if !d.Equal(tc.expected) {
+			t.Errorf("%s, expected %v got %v", tc.json, tc.expected, d.Time)
+		}
+		if _, offset := d.Zone(); offset != tc.offset {
+			t.Errorf("%s, expected offset %d got %d", tc.json, tc.offset, offset)
+		}
+	}
+}
+
+func TestJGDateMarshalJSON(t *testing.T) {
+	d := JGDate{time.Date(2016, 9, 24, 0, 0, 0, 0, time.FixedZone("", -5*3600))}
+	b, err := json.Marshal(d)
+	if err != nil || string(b) != `"/Date(1474693200000-0500)/"` {
+		t.Errorf("unexpected result %s %v", b, err)
+	}
+	b, err = json.Marshal(JGDate{})
+	if err != nil || string(b) != "null" {
+		t.Errorf("expected null, got %s %v", b, err)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	if _, err := ParseDate(""); err == nil || err.Error() != "no value set for date" {
+		t.Errorf("unexpected error %v", err)
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(`{"startDate": "/Date(1474675200000+0000)/", "expiryDate": null}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.ParseStartDate(); err != nil {
+		t.Error(err)
+	}
+	if _, err := e.ParseExpiryDate(); err == nil || err.Error() != "no value set for ExpiryDate" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func FuzzJGDate(f *testing.F) {
+	for _, seed := range []string{
+		"/Date(1474675200000+0000)/",
+		"/Date(1474675200123-0500)/",
+		"/Date(-86400000)/",
+		"2016-09-24T01:00:00+01:00",
+		"2016-09-24",
+		"",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Skip()
+		}
+		var d JGDate
+		if err = json.Unmarshal(b, &d); err != nil {
+			return
+		}
+		// a decoded date must survive a round trip to the nearest millisecond
+		b, err = json.Marshal(d)
+		if err != nil {
+			t.Fatalf("%q, error marshalling %v", s, err)
+		}
+		var rt JGDate
+		if err = json.Unmarshal(b, &rt); err != nil {
+			t.Fatalf("%q, error unmarshalling %s %v", s, b, err)
+		}
+		if !rt.Equal(d.Truncate(time.Millisecond)) {
+			t.Fatalf("%q, round trip %s gave %v instead of %v", s, b, rt.Time, d.Time)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Donation represents a JustGiving donation
+type Donation struct {
+	ID                     uint   `json:"id"`
+	Ref                    string `json:"donationRef"`
+	Amount                 string `json:"amount"`
+	CurrencyCode           string `json:"currencyCode"`
+	DonorLocalAmount       string `json:"donorLocalAmount"`
+	DonorLocalCurrencyCode string `json:"donorLocalCurrencyCode"`
+	EstimatedTaxReclaim    string `json:"estimatedTaxReclaim"`
+	DonationDate           string `json:"donationDate"`
+	DonorDisplayName       string `json:"donorDisplayName"`
+	Message                string `json:"message"`
+	Image                  string `json:"image"`
+	Source                 string `json:"source"`
+	Status                 string `json:"status"`
+	CharityID              uint   `json:"charityId"`
+	PageShortName          string `json:"pageShortName"`
+	ThirdPartyReference    string `json:"thirdPartyReference"`
+}
+
+// ParseDonationDate attempts to convert the DonationDate returned by JustGiving to a Time
+func (d Donation) ParseDonationDate() (time.Time, error) {
+	return ParseDate(d.DonationDate)
+}
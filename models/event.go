@@ -7,24 +7,24 @@ type Event struct {
 	ID             uint   `json:"id"`
 	Name           string `json:"name"`
 	Description    string `json:"description"`
-	CompletionDate string `json:"completionDate"`
-	ExpiryDate     string `json:"expiryDate"`
-	StartDate      string `json:"startDate"`
+	CompletionDate JGDate `json:"completionDate"`
+	ExpiryDate     JGDate `json:"expiryDate"`
+	StartDate      JGDate `json:"startDate"`
 	Type           string `json:"eventType"`
 	Location       string `json:"location"`
 }
 
-// ParseCompletionDate attempts to convert the EventDate returned by JustGiving to a Time
+// ParseCompletionDate returns the CompletionDate returned by JustGiving as a Time
 func (e Event) ParseCompletionDate() (time.Time, error) {
-	return ParseDate(e.CompletionDate)
+	return e.CompletionDate.parse("CompletionDate")
 }
 
-// ParseExpiryDate attempts to convert the EventDate returned by JustGiving to a Time
+// ParseExpiryDate returns the ExpiryDate returned by JustGiving as a Time
 func (e Event) ParseExpiryDate() (time.Time, error) {
-	return ParseDate(e.ExpiryDate)
+	return e.ExpiryDate.parse("ExpiryDate")
 }
 
-// ParseStartDate attempts to convert the EventDate returned by JustGiving to a Time
+// ParseStartDate returns the StartDate returned by JustGiving as a Time
 func (e Event) ParseStartDate() (time.Time, error) {
-	return ParseDate(e.StartDate)
+	return e.StartDate.parse("StartDate")
 }
@@ -0,0 +1,62 @@
+package models
+
+// FundraisingPageForCampaignValidationService defines the validation methods requiring calls to the JustGiving API.
+//
+// For an implementation see justin.Service
+type FundraisingPageForCampaignValidationService interface {
+	IsValidCurrencyCode(currencyCode string) (bool, error)
+}
+
+// FundraisingPageForCampaign represents a JustGiving fundraising page for a JustGiving charity campaign
+type FundraisingPageForCampaign struct {
+	// CampaignID is the campaign guid as returned in models.Campaign
+	CampaignID string
+
+	CharityID uint
+
+	PageShortName string
+
+	PageTitle string
+
+	PageStory string
+
+	Images []Image
+
+	// CustomCodes are optional, only the codes set are sent
+	CustomCodes CustomCodes
+
+	// TargetAmount for this fundraising effort expressed as a valid currency amount e.g. "999.99" or "9999"
+	TargetAmount string
+
+	// Target for this fundraising effort, when set it is used instead of the TargetAmount (and its Currency instead of the CurrencyCode)
+	Target *Money
+
+	// CurrencyCode
+	CurrencyCode string
+
+	CharityFunded bool
+
+	JustGivingOptIn bool
+
+	CharityOptIn bool
+
+	TeamID uint
+}
+
+// HasValidCurrencyCode checks the CurrencyCode (or Target currency) is in the published JustGiving currency code list
+func (fp FundraisingPageForCampaign) HasValidCurrencyCode(vs FundraisingPageForCampaignValidationService) (bool, error) {
+	if fp.Target != nil && fp.Target.Currency != "" {
+		return vs.IsValidCurrencyCode(fp.Target.Currency)
+	}
+	return vs.IsValidCurrencyCode(fp.CurrencyCode)
+}
+
+// HasValidTargetAmount checks the TargetAmount (or Target) is a positive currency amount, in the CurrencyCode if both are set
+func (fp FundraisingPageForCampaign) HasValidTargetAmount() bool {
+	return validTarget(fp.TargetAmount, fp.Target, fp.CurrencyCode)
+}
+
+// DefaultImage returns the index of the image to mark as the default, the first image marked IsDefault or the first image if none are
+func (fp FundraisingPageForCampaign) DefaultImage() int {
+	return defaultImage(fp.Images)
+}
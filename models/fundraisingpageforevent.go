@@ -1,9 +1,5 @@
 package models
 
-import (
-	"strconv"
-)
-
 // FundraisingPageForEventValidationService defines the validation methods requiring calls to the JustGiving API.
 //
 // For an implementation see justin.Service
@@ -25,11 +21,15 @@ type FundraisingPageForEvent struct {
 
 	Images []Image
 
-	CustomCodes [6]string
+	// CustomCodes are optional, only the codes set are sent
+	CustomCodes CustomCodes
 
 	// TargetAmount for this fundraising effort expressed as a valid currency amount e.g. "999.99" or "9999"
 	TargetAmount string
 
+	// Target for this fundraising effort, when set it is used instead of the TargetAmount (and its Currency instead of the CurrencyCode)
+	Target *Money
+
 	// CurrencyCode
 	CurrencyCode string
 
@@ -40,21 +40,47 @@ type FundraisingPageForEvent struct {
 	CharityOptIn bool
 
 	TeamID uint
+
+	// ActivityType is optional, e.g. ActivityTypeInMemory, ActivityTypeBirthday or ActivityTypeWedding
+	ActivityType ActivityType
+
+	// EventName is optional, used for pages whose event has no fixed name
+	EventName string
+
+	// EventDate is optional, the date of the fundraising activity
+	EventDate JGDate
+
+	// ExpiryDate is optional, the date the page closes
+	ExpiryDate JGDate
+
+	// Reference is optional, your own reference for the page
+	Reference string
+
+	// RememberedPerson is optional, the person an in memory page remembers (required when the ActivityType is ActivityTypeInMemory,
+	// which is the default ActivityType when it is set)
+	RememberedPerson *RememberedPerson
+
+	// RememberedPersonRelationship is optional, the relationship of the page owner to the RememberedPerson e.g. "Mother"
+	RememberedPersonRelationship string
+
+	// Tags are optional
+	Tags []Tag
 }
 
-// HasValidCurrencyCode checks the CurrencyCode is in the published JustGiving currency code list
+// HasValidCurrencyCode checks the CurrencyCode (or Target currency) is in the published JustGiving currency code list
 func (fp FundraisingPageForEvent) HasValidCurrencyCode(vs FundraisingPageForEventValidationService) (bool, error) {
+	if fp.Target != nil && fp.Target.Currency != "" {
+		return vs.IsValidCurrencyCode(fp.Target.Currency)
+	}
 	return vs.IsValidCurrencyCode(fp.CurrencyCode)
 }
 
-// HasValidTargetAmount performs basic validation on the TargetAmount
+// HasValidTargetAmount checks the TargetAmount (or Target) is a positive currency amount, in the CurrencyCode if both are set
 func (fp FundraisingPageForEvent) HasValidTargetAmount() bool {
-	if fp.TargetAmount == "" {
-		return true
-	}
-	_, err := strconv.ParseFloat(fp.TargetAmount, 64)
-	if err != nil {
-		return false
-	}
-	return true
+	return validTarget(fp.TargetAmount, fp.Target, fp.CurrencyCode)
+}
+
+// DefaultImage returns the index of the image to mark as the default, the first image marked IsDefault or the first image if none are
+func (fp FundraisingPageForEvent) DefaultImage() int {
+	return defaultImage(fp.Images)
 }
@@ -10,11 +10,61 @@ type FundraisingResults struct {
 	TotalRaisedOnline             string `json:"totalRaisedOnline"`
 	TotalRaisedSMS                string `json:"totalRaisedSms"`
 	TotalEstimatedGiftAid         string `json:"totalEstimatedGiftAid"`
-	EventDate                     string `json:"eventDate"`
+	CurrencyCode                  string `json:"currencyCode"`
+	EventDate                     JGDate `json:"eventDate"`
 	PageCancelled                 bool
 }
 
-// ParseEventDate attempts to convert the EventDate returned by JustGiving to a Time
+// ParseEventDate returns the EventDate returned by JustGiving as a Time
 func (r FundraisingResults) ParseEventDate() (time.Time, error) {
-	return ParseDate(r.EventDate)
+	return r.EventDate.parse("EventDate")
+}
+
+// TargetMoney returns the Target as Money in the CurrencyCode of the page
+func (r FundraisingResults) TargetMoney() (Money, error) {
+	return r.money(r.Target)
+}
+
+// TotalRaisedOfflineMoney returns the TotalRaisedOffline as Money in the CurrencyCode of the page
+func (r FundraisingResults) TotalRaisedOfflineMoney() (Money, error) {
+	return r.money(r.TotalRaisedOffline)
+}
+
+// TotalRaisedOnlineMoney returns the TotalRaisedOnline as Money in the CurrencyCode of the page
+func (r FundraisingResults) TotalRaisedOnlineMoney() (Money, error) {
+	return r.money(r.TotalRaisedOnline)
+}
+
+// TotalRaisedSMSMoney returns the TotalRaisedSMS as Money in the CurrencyCode of the page
+func (r FundraisingResults) TotalRaisedSMSMoney() (Money, error) {
+	return r.money(r.TotalRaisedSMS)
+}
+
+// TotalEstimatedGiftAidMoney returns the TotalEstimatedGiftAid as Money in the CurrencyCode of the page, rounded to the minor unit
+func (r FundraisingResults) TotalEstimatedGiftAidMoney() (Money, error) {
+	if r.TotalEstimatedGiftAid == "" {
+		return NewMoney(0, r.CurrencyCode), nil
+	}
+	return ParseMoneyRounded(r.TotalEstimatedGiftAid, r.CurrencyCode)
+}
+
+// TotalRaisedMoney returns the sum of the TotalRaisedOnline, TotalRaisedOffline and TotalRaisedSMS (excluding Gift Aid)
+func (r FundraisingResults) TotalRaisedMoney() (Money, error) {
+	var amounts []Money
+	for _, amount := range []string{r.TotalRaisedOnline, r.TotalRaisedOffline, r.TotalRaisedSMS} {
+		m, err := r.money(amount)
+		if err != nil {
+			return m, err
+		}
+		amounts = append(amounts, m)
+	}
+	return SumMoney(amounts...)
+}
+
+// money parses an amount of the results, JustGiving returns an empty string for no amount
+func (r FundraisingResults) money(amount string) (Money, error) {
+	if amount == "" {
+		return NewMoney(0, r.CurrencyCode), nil
+	}
+	return ParseMoney(amount, r.CurrencyCode)
 }
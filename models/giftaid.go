@@ -0,0 +1,200 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GiftAidBasicRate is the UK basic rate of income tax in percent, used by default to estimate Gift Aid
+const GiftAidBasicRate = 20
+
+// GiftAidCurrency is the only currency of donations which can attract Gift Aid
+const GiftAidCurrency = "GBP"
+
+// GiftAidUplift returns the Gift Aid a charity can claim on a donation at the basic rate of income tax (in percent), rounded down to the minor unit
+//
+// e.g. at the basic rate of 20% a £10.00 donation attracts £2.50 of Gift Aid, the tax paid on the £12.50 the donor earned to give it.
+func GiftAidUplift(donation Money, basicRate int64) (Money, error) {
+	if basicRate <= 0 || basicRate >= 100 {
+		return Money{}, fmt.Errorf("invalid basic rate %d%%", basicRate)
+	}
+	m, err := donation.Mul(basicRate)
+	if err != nil {
+		return m, err
+	}
+	m.Minor /= 100 - basicRate
+	return m, nil
+}
+
+// GiftAidTotals is a Gift Aid breakdown of the donations to one or more fundraising pages
+//
+// Raised and ReportedGiftAid are the page totals reported by JustGiving, the other amounts are calculated from the donations.
+type GiftAidTotals struct {
+	// Raised is the total raised by the pages online, offline and by SMS
+	Raised Money
+	// ReportedGiftAid is the Gift Aid estimated by JustGiving in the page totals
+	ReportedGiftAid Money
+
+	// Donations is the number of donations, excluding those which were refunded, cancelled or rejected
+	Donations int
+	// Eligible is the total of the donations in GBP, which can attract Gift Aid if the donor is a UK taxpayer
+	Eligible Money
+	// Claimed is the total of the donations with Gift Aid claimed by the donor
+	Claimed Money
+	// ClaimedDonations is the number of donations with Gift Aid claimed by the donor
+	ClaimedDonations int
+	// ClaimedGiftAid is the Gift Aid JustGiving estimates will be reclaimed on the Claimed donations
+	ClaimedGiftAid Money
+	// EstimatedGiftAid is the Gift Aid on the Claimed donations at the basic rate of the report
+	EstimatedGiftAid Money
+	// UnclaimedGiftAid is the Gift Aid at the basic rate of the report on the Eligible donations which did not claim it
+	UnclaimedGiftAid Money
+}
+
+// add adds the totals of o to t
+func (t *GiftAidTotals) add(o GiftAidTotals) error {
+	var err error
+	if t.Raised, err = t.Raised.Add(o.Raised); err != nil {
+		return err
+	}
+	if t.ReportedGiftAid, err = t.ReportedGiftAid.Add(o.ReportedGiftAid); err != nil {
+		return err
+	}
+	t.Donations += o.Donations
+	t.ClaimedDonations += o.ClaimedDonations
+	for _, sum := range []struct {
+		total *Money
+		m     Money
+	}{
+		{&t.Eligible, o.Eligible},
+		{&t.Claimed, o.Claimed},
+		{&t.ClaimedGiftAid, o.ClaimedGiftAid},
+		{&t.EstimatedGiftAid, o.EstimatedGiftAid},
+		{&t.UnclaimedGiftAid, o.UnclaimedGiftAid},
+	} {
+		if *sum.total, err = sum.total.Add(sum.m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GiftAidReport reports the Gift Aid on fundraising pages, with totals per page, per event and overall
+type GiftAidReport struct {
+	// BasicRate is the basic rate of income tax in percent used to estimate Gift Aid
+	BasicRate int64
+	// Pages are the totals of each page keyed by page short name
+	Pages map[string]*GiftAidTotals
+	// Events are the totals of the pages of each event keyed by event id
+	Events map[uint]*GiftAidTotals
+	// Total is the total of all the pages
+	Total GiftAidTotals
+}
+
+// NewGiftAidReport returns an empty GiftAidReport estimating Gift Aid at the specified basic rate of income tax (in percent), or GiftAidBasicRate if 0
+func NewGiftAidReport(basicRate int64) *GiftAidReport {
+	if basicRate == 0 {
+		basicRate = GiftAidBasicRate
+	}
+	return &GiftAidReport{
+		BasicRate: basicRate,
+		Pages:     make(map[string]*GiftAidTotals),
+		Events:    make(map[uint]*GiftAidTotals),
+	}
+}
+
+// AddPage adds a fundraising page of an event to the report, using its page totals and the donations made to it
+//
+// Either the results or donations may be empty, adding the same page again adds to its totals. ErrCurrencyMismatch is returned if the page raised
+// a different currency to the other pages of the report.
+func (r *GiftAidReport) AddPage(shortName string, eventID uint, results FundraisingResults, donations []Donation) error {
+	page, err := r.pageTotals(results, donations)
+	if err != nil {
+		return fmt.Errorf("error reporting Gift Aid for page %s, %w", shortName, err)
+	}
+	// add to copies of the totals so the report is unchanged on error
+	var pageTotals, eventTotals GiftAidTotals
+	if t := r.Pages[shortName]; t != nil {
+		pageTotals = *t
+	}
+	if t := r.Events[eventID]; t != nil {
+		eventTotals = *t
+	}
+	total := r.Total
+	if err = pageTotals.add(page); err != nil {
+		return fmt.Errorf("error reporting Gift Aid for page %s, %w", shortName, err)
+	}
+	if err = eventTotals.add(page); err != nil {
+		return fmt.Errorf("error reporting Gift Aid for event %d, %w", eventID, err)
+	}
+	if err = total.add(page); err != nil {
+		return fmt.Errorf("error reporting Gift Aid total, %w", err)
+	}
+	r.Pages[shortName], r.Events[eventID], r.Total = &pageTotals, &eventTotals, total
+	return nil
+}
+
+func (r *GiftAidReport) pageTotals(results FundraisingResults, donations []Donation) (GiftAidTotals, error) {
+	var t GiftAidTotals
+	var err error
+	if t.Raised, err = results.TotalRaisedMoney(); err != nil {
+		return t, err
+	}
+	if t.ReportedGiftAid, err = results.TotalEstimatedGiftAidMoney(); err != nil {
+		return t, err
+	}
+	if t.ReportedGiftAid.IsZero() {
+		// pages in other currencies report no Gift Aid, which can be added to any currency
+		t.ReportedGiftAid = Money{}
+	}
+	t.Eligible, t.Claimed, t.ClaimedGiftAid, t.EstimatedGiftAid, t.UnclaimedGiftAid = gbp(), gbp(), gbp(), gbp(), gbp()
+	for _, d := range donations {
+		switch strings.ToLower(d.Status) {
+		case "refunded", "cancelled", "rejected":
+			continue
+		}
+		t.Donations++
+		if !strings.EqualFold(d.CurrencyCode, GiftAidCurrency) {
+			continue
+		}
+		amount, err := ParseMoney(d.Amount, GiftAidCurrency)
+		if err != nil {
+			return t, fmt.Errorf("invalid donation %d, %v", d.ID, err)
+		}
+		uplift, err := GiftAidUplift(amount, r.BasicRate)
+		if err != nil {
+			return t, err
+		}
+		var reclaim Money
+		if strings.TrimSpace(d.EstimatedTaxReclaim) != "" {
+			// reclaims are a quarter of the donation so are often more precise than a penny
+			if reclaim, err = ParseMoneyRounded(d.EstimatedTaxReclaim, GiftAidCurrency); err != nil {
+				return t, fmt.Errorf("invalid donation %d, %v", d.ID, err)
+			}
+		}
+		if t.Eligible, err = t.Eligible.Add(amount); err != nil {
+			return t, err
+		}
+		if reclaim.IsZero() {
+			if t.UnclaimedGiftAid, err = t.UnclaimedGiftAid.Add(uplift); err != nil {
+				return t, err
+			}
+			continue
+		}
+		t.ClaimedDonations++
+		if t.Claimed, err = t.Claimed.Add(amount); err != nil {
+			return t, err
+		}
+		if t.ClaimedGiftAid, err = t.ClaimedGiftAid.Add(reclaim); err != nil {
+			return t, err
+		}
+		if t.EstimatedGiftAid, err = t.EstimatedGiftAid.Add(uplift); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+func gbp() Money {
+	return Money{Currency: GiftAidCurrency}
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+func TestGiftAidUplift(t *testing.T) {
+	for _, tc := range []struct {
+		amount    string
+		basicRate int64
+		expected  string
+		valid     bool
+	}{
+		{"10.00", 20, "2.50", true},
+		{"10.50", 20, "2.62", true},
+		{"33.33", 20, "8.33", true},
+		{"10.00", 22, "2.82", true},
+		{"0.00", 20, "0.00", true},
+		{"10.00", 0, "", false},
+		{"10.00", 100, "", false},
+	} {
+		m, _ := ParseMoney(tc.amount, "GBP")
+		uplift, err := GiftAidUplift(m, tc.basicRate)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s at %d%%, unexpected error %v", tc.amount, tc.basicRate, err)
+			continue
+		}
+		if tc.valid && uplift.String() != tc.expected {
+			t.Errorf("%s at %d%%, expected %s, got %s", tc.amount, tc.basicRate, tc.expected, uplift)
+		}
+	}
+}
+
+// giftAidReportFrom returns a report of the pages in a fixture of page results and donations
+func giftAidReportFrom(t *testing.T, fixture string) *GiftAidReport {
+	b, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []struct {
+		ShortName string             `json:"pageShortName"`
+		EventID   uint               `json:"eventId"`
+		Results   FundraisingResults `json:"results"`
+		Donations []Donation         `json:"donations"`
+	}
+	if err = json.Unmarshal(b, &pages); err != nil {
+		t.Fatal(err)
+	}
+	report := NewGiftAidReport(0)
+	for _, p := range pages {
+		if err = report.AddPage(p.ShortName, p.EventID, p.Results, p.Donations); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return report
+}
+
+type expectedGiftAid struct {
+	raised, reported, eligible, claimed, claimedGiftAid, estimated, unclaimed string
+	donations, claimedDonations                                               int
+}
+
+func checkGiftAidTotals(t *testing.T, name string, totals *GiftAidTotals, e expectedGiftAid) {
+	if totals == nil {
+		t.Errorf("%s, missing totals", name)
+		return
+	}
+	for _, amount := range []struct {
+		field    string
+		m        Money
+		expected string
+	}{
+		{"Raised", totals.Raised, e.raised},
+		{"ReportedGiftAid", totals.ReportedGiftAid, e.reported},
+		{"Eligible", totals.Eligible, e.eligible},
+		{"Claimed", totals.Claimed, e.claimed},
+		{"ClaimedGiftAid", totals.ClaimedGiftAid, e.claimedGiftAid},
+		{"EstimatedGiftAid", totals.EstimatedGiftAid, e.estimated},
+		{"UnclaimedGiftAid", totals.UnclaimedGiftAid, e.unclaimed},
+	} {
+		if amount.m.String() != amount.expected {
+			t.Errorf("%s, expected %s %s, got %s", name, amount.field, amount.expected, amount.m)
+		}
+	}
+	if totals.Donations != e.donations || totals.ClaimedDonations != e.claimedDonations {
+		t.Errorf("%s, expected %d donations (%d claimed), got %d (%d)", name, e.donations, e.claimedDonations, totals.Donations, totals.ClaimedDonations)
+	}
+}
+
+func TestGiftAidReport(t *testing.T) {
+	report := giftAidReportFrom(t, "testdata/giftaid_pages.json")
+	checkGiftAidTotals(t, "london-runner", report.Pages["london-runner"], expectedGiftAid{"120.50", "18.75", "85.50", "75.00", "18.75", "18.75", "2.62", 4, 2})
+	checkGiftAidTotals(t, "london-walker", report.Pages["london-walker"], expectedGiftAid{"33.33", "8.33", "33.33", "33.33", "8.33", "8.33", "0.00", 1, 1})
+	checkGiftAidTotals(t, "swimmer", report.Pages["swimmer"], expectedGiftAid{"7.00", "0.00", "7.00", "0.00", "0.00", "0.00", "1.75", 1, 0})
+	checkGiftAidTotals(t, "event 100", report.Events[100], expectedGiftAid{"153.83", "27.08", "118.83", "108.33", "27.08", "27.08", "2.62", 5, 3})
+	checkGiftAidTotals(t, "event 200", report.Events[200], expectedGiftAid{"7.00", "0.00", "7.00", "0.00", "0.00", "0.00", "1.75", 1, 0})
+	checkGiftAidTotals(t, "total", &report.Total, expectedGiftAid{"160.83", "27.08", "125.83", "108.33", "27.08", "27.08", "4.37", 6, 3})
+	if report.Total.Raised.Currency != "GBP" || report.Total.UnclaimedGiftAid.Currency != GiftAidCurrency {
+		t.Errorf("expected totals in GBP, got %+v", report.Total)
+	}
+
+	euros := FundraisingResults{TotalRaisedOnline: "10.00", CurrencyCode: "EUR"}
+	if err := report.AddPage("paris-runner", 100, euros, nil); err == nil {
+		t.Error("expected a page raising a different currency to be rejected")
+	}
+	if _, ok := report.Pages["paris-runner"]; ok {
+		t.Error("expected the report to be unchanged by a rejected page")
+	}
+}
+
+func TestGiftAidReportSubPennyReclaims(t *testing.T) {
+	// JustGiving reports the reclaim on a donation to 4 decimal places, e.g. 8.3325 on 33.33
+	report := giftAidReportFrom(t, "testdata/giftaid_subpenny.json")
+	checkGiftAidTotals(t, "marathon", report.Pages["marathon"], expectedGiftAid{"44.33", "11.08", "44.33", "44.33", "11.08", "11.07", "0.00", 3, 3})
+	checkGiftAidTotals(t, "total", &report.Total, expectedGiftAid{"44.33", "11.08", "44.33", "44.33", "11.08", "11.07", "0.00", 3, 3})
+}
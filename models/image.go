@@ -8,4 +8,16 @@ import (
 type Image struct {
 	Caption string
 	URL     url.URL
+	// IsDefault marks the image shown by default on the page, at most one image can be the default (the first image is used when none are)
+	IsDefault bool
+}
+
+// defaultImage returns the index of the first image marked IsDefault, or the first image if none are
+func defaultImage(images []Image) int {
+	for i, img := range images {
+		if img.IsDefault {
+			return i
+		}
+	}
+	return 0
 }
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ActivityType is the type of fundraising activity of a page
+type ActivityType string
+
+// Activity types supported by JustGiving for occasion and in memory pages
+const (
+	ActivityTypeInMemory         ActivityType = "InMemory"
+	ActivityTypeBirthday         ActivityType = "Birthday"
+	ActivityTypeWedding          ActivityType = "Wedding"
+	ActivityTypeAnniversary      ActivityType = "Anniversary"
+	ActivityTypeChristening      ActivityType = "Christening"
+	ActivityTypeOtherCelebration ActivityType = "OtherCelebration"
+)
+
+// RememberedPerson is the person remembered by an in memory page
+//
+// ID is set for a person already known to JustGiving (see justin.Service SearchInMemoryPersons), otherwise a new tribute is created from the other fields.
+type RememberedPerson struct {
+	ID          uint   `json:"id,omitempty"`
+	FirstName   string `json:"firstName,omitempty"`
+	LastName    string `json:"lastName,omitempty"`
+	Town        string `json:"town,omitempty"`
+	DateOfBirth JGDate `json:"dateOfBirth"`
+	DateOfDeath JGDate `json:"dateOfDeath"`
+}
+
+// MarshalJSON encodes the person, leaving out the dates which are not set
+func (p RememberedPerson) MarshalJSON() ([]byte, error) {
+	type person RememberedPerson
+	v := struct {
+		person
+		DateOfBirth *JGDate `json:"dateOfBirth,omitempty"`
+		DateOfDeath *JGDate `json:"dateOfDeath,omitempty"`
+	}{person: person(p)}
+	if !p.DateOfBirth.IsZero() {
+		v.DateOfBirth = &p.DateOfBirth
+	}
+	if !p.DateOfDeath.IsZero() {
+		v.DateOfDeath = &p.DateOfDeath
+	}
+	return json.Marshal(v)
+}
+
+// Name returns the full name of the person
+func (p RememberedPerson) Name() string {
+	if p.LastName == "" {
+		return p.FirstName
+	}
+	if p.FirstName == "" {
+		return p.LastName
+	}
+	return p.FirstName + " " + p.LastName
+}
+
+// validateInMemory checks the in memory fields of a fundraising page
+func validateInMemory(ve *ValidationErrors, activityType ActivityType, person *RememberedPerson, relationship string) {
+	if person == nil {
+		if activityType == ActivityTypeInMemory {
+			ve.add("RememberedPerson", ValidationRequired, "is required for an in memory page")
+		}
+		if relationship != "" {
+			ve.add("RememberedPersonRelationship", ValidationInvalidFormat, "requires a RememberedPerson")
+		}
+		return
+	}
+	if activityType != "" && activityType != ActivityTypeInMemory {
+		ve.add("ActivityType", ValidationInvalidFormat, fmt.Sprintf("must be %s for a page remembering a person", ActivityTypeInMemory))
+	}
+	ve.maxLength("RememberedPersonRelationship", relationship, MaxNameLength)
+	// an existing person only needs their ID
+	if person.ID == 0 {
+		if ve.required("RememberedPerson.FirstName", person.FirstName) {
+			ve.maxLength("RememberedPerson.FirstName", person.FirstName, MaxNameLength)
+		}
+		if ve.required("RememberedPerson.LastName", person.LastName) {
+			ve.maxLength("RememberedPerson.LastName", person.LastName, MaxNameLength)
+		}
+		ve.maxLength("RememberedPerson.Town", person.Town, MaxTownLength)
+	}
+	now := time.Now()
+	if person.DateOfBirth.After(now) {
+		ve.add("RememberedPerson.DateOfBirth", ValidationInvalidDate, "must not be in the future")
+	}
+	if person.DateOfDeath.After(now) {
+		ve.add("RememberedPerson.DateOfDeath", ValidationInvalidDate, "must not be in the future")
+	}
+	if !person.DateOfBirth.IsZero() && !person.DateOfDeath.IsZero() && person.DateOfDeath.Before(person.DateOfBirth.Time) {
+		ve.add("RememberedPerson.DateOfDeath", ValidationInvalidDate, "must not be before the DateOfBirth")
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateInMemory(t *testing.T) {
+	born := JGDate{Time: time.Date(1950, 1, 2, 0, 0, 0, 0, time.UTC)}
+	died := JGDate{Time: time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC)}
+	future := JGDate{Time: time.Now().AddDate(1, 0, 0)}
+	for _, tc := range []struct {
+		activityType ActivityType
+		person       *RememberedPerson
+		relationship string
+		expected     map[string]string
+	}{
+		{ActivityTypeInMemory, &RememberedPerson{FirstName: "Ada", LastName: "Lovelace", DateOfBirth: born, DateOfDeath: died}, "Mother", map[string]string{}},
+		{ActivityTypeInMemory, &RememberedPerson{ID: 7}, "", map[string]string{}},
+		{"", &RememberedPerson{ID: 7}, "", map[string]string{}},
+		{ActivityTypeBirthday, nil, "", map[string]string{}},
+		{ActivityTypeInMemory, nil, "Mother", map[string]string{
+			"RememberedPerson":             ValidationRequired,
+			"RememberedPersonRelationship": ValidationInvalidFormat,
+		}},
+		{ActivityTypeWedding, &RememberedPerson{LastName: "Lovelace", DateOfBirth: died, DateOfDeath: born}, "", map[string]string{
+			"ActivityType":                 ValidationInvalidFormat,
+			"RememberedPerson.FirstName":   ValidationRequired,
+			"RememberedPerson.DateOfDeath": ValidationInvalidDate,
+		}},
+		{ActivityTypeInMemory, &RememberedPerson{ID: 7, DateOfBirth: future}, "", map[string]string{
+			"RememberedPerson.DateOfBirth": ValidationInvalidDate,
+		}},
+	} {
+		var ve ValidationErrors
+		validateInMemory(&ve, tc.activityType, tc.person, tc.relationship)
+		codes := validationCodes(t, ve.err())
+		if len(codes) != len(tc.expected) {
+			t.Errorf("%s %+v, expected %v, got %v", tc.activityType, tc.person, tc.expected, codes)
+			continue
+		}
+		for field, code := range tc.expected {
+			if codes[field] != code {
+				t.Errorf("%s %+v, expected %s %s, got %s", tc.activityType, tc.person, field, code, codes[field])
+			}
+		}
+	}
+}
@@ -3,38 +3,16 @@ package models
 
 import (
 	"errors"
-	"strconv"
-	"strings"
 	"time"
 )
 
 // ParseDate attempts to convert the date string returned by JustGiving to a Time
 //
-// The raw date is returned in the follwing format `/Date(1474675200000+0000)/``
-//
-// Parsing is carried out as follows:
-// 	- Remove the leading `/Date(` string
-//  - Extract the remaining integer value prefixing the `+`
-//  - Divide this number by 1000
-//  - Use https://golang.org/pkg/time/#Time.Unix to return a Time from the result
+// The raw date is usually returned in the Microsoft JSON date format, e.g. `/Date(1474675200000+0000)/`,
+// the milliseconds since the Unix epoch followed by an optional timezone offset. See JGDate for the formats accepted.
 func ParseDate(date string) (time.Time, error) {
-	var result time.Time
-	s := date
-	if s == "" {
-		return result, errors.New("no value set for EventDate")
-	}
-	s = strings.Replace(s, "/Date(", "", -1)
-	i := strings.Index(s, "+")
-	if i < 4 {
-		return result, errors.New("invalid format for EventDate")
-	}
-	s = s[0:i]
-	ui, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		return result, err
-	}
-	if ui < 1000 {
-		return result, errors.New("invalid format for EventDate")
+	if date == "" {
+		return time.Time{}, errors.New("no value set for date")
 	}
-	return time.Unix(ui/1000, 0), nil
+	return parseJGDate(date)
 }
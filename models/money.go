@@ -0,0 +1,357 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount of a currency, held as a whole number of minor units (e.g. pence) to avoid floating point drift
+//
+// Currency is the ISO 4217 currency code, it may be empty when JustGiving does not return one alongside the amount.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// minorUnitDigits are the number of decimal places of currencies which don't have 2
+var minorUnitDigits = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// digits returns the number of decimal places used by the currency
+func digits(currency string) int {
+	if d, ok := minorUnitDigits[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// NewMoney returns the Money for the specified number of minor units of a currency
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney converts a currency amount as used by JustGiving, e.g. "999.99", "9999" or "1,234.50", to Money
+//
+// An optional leading minus sign is accepted, exponents, "NaN", "Inf" and amounts more precise than the currency's minor unit are not.
+func ParseMoney(amount string, currency string) (Money, error) {
+	return parseMoney(amount, currency, false)
+}
+
+// ParseMoneyRounded converts a currency amount as ParseMoney, but rounds amounts more precise than the currency's minor unit half away from zero
+//
+// JustGiving calculates some amounts as a percentage of a donation, e.g. an estimated tax reclaim of "8.3325" is £8.33.
+func ParseMoneyRounded(amount string, currency string) (Money, error) {
+	return parseMoney(amount, currency, true)
+}
+
+func parseMoney(amount string, currency string, round bool) (Money, error) {
+	result := Money{Currency: strings.ToUpper(currency)}
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+	whole, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if !validWhole(whole) || !allDigits(fraction) || (whole == "" && fraction == "") {
+		return result, fmt.Errorf("invalid amount %q", amount)
+	}
+	whole = strings.Replace(whole, ",", "", -1)
+	d := digits(currency)
+	// trailing zeros beyond the minor unit don't change the amount, e.g. "10.5000"
+	fraction = strings.TrimRight(fraction, "0")
+	roundUp := false
+	if len(fraction) > d {
+		if !round {
+			return result, fmt.Errorf("invalid amount %q, more than %d decimal places", amount, d)
+		}
+		roundUp = fraction[d] >= '5'
+		fraction = fraction[:d]
+	}
+	fraction += strings.Repeat("0", d-len(fraction))
+	n := whole + fraction
+	if n == "" {
+		n = "0"
+	}
+	minor, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		return result, fmt.Errorf("invalid amount %q", amount)
+	}
+	if roundUp {
+		if minor == math.MaxInt64 {
+			return result, fmt.Errorf("invalid amount %q", amount)
+		}
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	result.Minor = minor
+	return result, nil
+}
+
+// validWhole reports whether s is digits, optionally grouped in threes with commas
+func validWhole(s string) bool {
+	if !strings.Contains(s, ",") {
+		return allDigits(s)
+	}
+	groups := strings.Split(s, ",")
+	if len(groups[0]) < 1 || len(groups[0]) > 3 || !allDigits(groups[0]) {
+		return false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 || !allDigits(g) {
+			return false
+		}
+	}
+	return true
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the amount as a plain decimal, e.g. "1234.50", as expected by JustGiving
+func (m Money) String() string {
+	d := digits(m.Currency)
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	s := strconv.FormatUint(abs(minor), 10)
+	if d == 0 {
+		return sign + s
+	}
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+	return sign + s[:len(s)-d] + "." + s[len(s)-d:]
+}
+
+func abs(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+// ErrCurrencyMismatch is returned when combining Money of different currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// currency returns the currency of the result of combining m and o, an empty currency matches any other
+func (m Money) currency(o Money) (string, error) {
+	switch {
+	case m.Currency == "":
+		return o.Currency, nil
+	case o.Currency == "" || strings.EqualFold(m.Currency, o.Currency):
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w, %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+}
+
+// Add returns the sum of m and o, which must be the same currency
+func (m Money) Add(o Money) (Money, error) {
+	currency, err := m.currency(o)
+	if err != nil {
+		return m, err
+	}
+	sum := m.Minor + o.Minor
+	if (o.Minor > 0 && sum < m.Minor) || (o.Minor < 0 && sum > m.Minor) {
+		return m, errors.New("amount overflow")
+	}
+	return Money{Minor: sum, Currency: currency}, nil
+}
+
+// Sub returns the difference of m and o, which must be the same currency
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Minor == math.MinInt64 {
+		return m, errors.New("amount overflow")
+	}
+	return m.Add(Money{Minor: -o.Minor, Currency: o.Currency})
+}
+
+// Mul returns m multiplied by n
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Minor != 0 && n != 0 {
+		product := m.Minor * n
+		if product/n != m.Minor || (m.Minor == -1 && n == math.MinInt64) || (n == -1 && m.Minor == math.MinInt64) {
+			return m, errors.New("amount overflow")
+		}
+		return Money{Minor: product, Currency: m.Currency}, nil
+	}
+	return Money{Currency: m.Currency}, nil
+}
+
+// Cmp compares m and o, which must be the same currency, returning -1 if m is less than o, 0 if they are equal and +1 if m is greater
+func (m Money) Cmp(o Money) (int, error) {
+	if _, err := m.currency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Minor < o.Minor:
+		return -1, nil
+	case m.Minor > o.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// SumMoney returns the total of the amounts, which must all be the same currency
+func SumMoney(amounts ...Money) (Money, error) {
+	var total Money
+	for _, m := range amounts {
+		var err error
+		if total, err = total.Add(m); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// MarshalJSON encodes the amount as a JSON string, e.g. "1234.50", as used by JustGiving
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON decodes an amount returned by JustGiving, either a JSON string or number, null or an empty string are zero
+//
+// The Currency is not changed, so can be set before decoding to use the correct minor unit.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		m.Minor = 0
+		return nil
+	}
+	s := string(b)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if strings.TrimSpace(s) == "" {
+			m.Minor = 0
+			return nil
+		}
+	}
+	result, err := ParseMoney(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	m.Minor = result.Minor
+	return nil
+}
+
+// validTarget checks a fundraising page target is a positive currency amount, optionally provided as Money
+func validTarget(amount string, target *Money, currencyCode string) bool {
+	if target != nil {
+		if currencyCode != "" && target.Currency != "" && !strings.EqualFold(currencyCode, target.Currency) {
+			return false
+		}
+		return target.Minor > 0
+	}
+	if amount == "" {
+		return true
+	}
+	m, err := ParseMoney(amount, currencyCode)
+	if err != nil {
+		return false
+	}
+	return m.Minor > 0
+}
+
+type moneyFormat struct {
+	decimal   string
+	group     string
+	prefix    bool
+	separated bool
+}
+
+// moneyFormats are the conventions for formatting amounts, keyed by language
+var moneyFormats = map[string]moneyFormat{
+	"en": {decimal: ".", group: ",", prefix: true},
+	"de": {decimal: ",", group: ".", separated: true},
+	"es": {decimal: ",", group: ".", separated: true},
+	"it": {decimal: ",", group: ".", separated: true},
+	"pt": {decimal: ",", group: ".", separated: true},
+	"da": {decimal: ",", group: ".", separated: true},
+	"fr": {decimal: ",", group: "\u00a0", separated: true},
+	"nl": {decimal: ",", group: ".", prefix: true, separated: true},
+}
+
+var currencySymbols = map[string]string{
+	"AUD": "A$", "CAD": "CA$", "EUR": "€", "GBP": "£", "HKD": "HK$", "JPY": "¥", "NZD": "NZ$", "SGD": "S$", "USD": "$", "ZAR": "R",
+}
+
+// Format returns the amount formatted for display in the specified locale, e.g. "£1,234.50" for "en-GB" or "1.234,50 €" for "de-DE"
+//
+// Only the language of the locale is used, unknown languages are formatted as English. Symbols are separated by a non-breaking space where required.
+func (m Money) Format(locale string) string {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	f, ok := moneyFormats[language]
+	if !ok {
+		f = moneyFormats["en"]
+	}
+
+	s := m.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(f.group)
+		}
+		grouped.WriteRune(r)
+	}
+	amount := grouped.String()
+	if fraction != "" {
+		amount += f.decimal + fraction
+	}
+
+	symbol, ok := currencySymbols[m.Currency]
+	if !ok {
+		symbol = m.Currency
+	}
+	space := ""
+	if f.separated || (!ok && symbol != "") {
+		space = "\u00a0"
+	}
+	switch {
+	case symbol == "":
+		return sign + amount
+	case f.prefix:
+		return sign + symbol + space + amount
+	}
+	return sign + amount + space + symbol
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	for _, tc := range []struct {
+		amount   string
+		currency string
+		minor    int64
+		valid    bool
+	}{
+		{"999.99", "GBP", 99999, true},
+		{"9999", "GBP", 999900, true},
+		{"1,234.50", "GBP", 123450, true},
+		{"0.1", "GBP", 10, true},
+		{".5", "GBP", 50, true},
+		{"10.5000", "GBP", 1050, true},
+		{"-2.50", "GBP", -250, true},
+		{"1000", "JPY", 1000, true},
+		{"1.234", "KWD", 1234, true},
+		{"1.001", "GBP", 0, false},
+		{"NaN", "GBP", 0, false},
+		{"Inf", "GBP", 0, false},
+		{"1e9", "GBP", 0, false},
+		{"1,23", "GBP", 0, false},
+		{"£10", "GBP", 0, false},
+		{"", "GBP", 0, false},
+		{"99999999999999999999", "GBP", 0, false},
+	} {
+		m, err := ParseMoney(tc.amount, tc.currency)
+		if (err == nil) != tc.valid {
+			t.Errorf("ParseMoney(%q), unexpected error %v", tc.amount, err)
+			continue
+		}
+		if tc.valid && m.Minor != tc.minor {
+			t.Errorf("ParseMoney(%q), expected %d minor units, got %d", tc.amount, tc.minor, m.Minor)
+		}
+	}
+}
+
+func TestParseMoneyRounded(t *testing.T) {
+	for _, tc := range []struct {
+		amount   string
+		currency string
+		minor    int64
+		valid    bool
+	}{
+		{"8.3325", "GBP", 833, true},
+		{"2.5050", "GBP", 251, true},
+		{"0.2475", "GBP", 25, true},
+		{"0.0049", "GBP", 0, true},
+		{"-8.335", "GBP", -834, true},
+		{"1.2345", "KWD", 1235, true},
+		{"12.50", "GBP", 1250, true},
+		{"1e-3", "GBP", 0, false},
+		{"", "GBP", 0, false},
+		{"92233720368547758.079", "GBP", 0, false},
+	} {
+		m, err := ParseMoneyRounded(tc.amount, tc.currency)
+		if (err == nil) != tc.valid {
+			t.Errorf("ParseMoneyRounded(%q), unexpected error %v", tc.amount, err)
+			continue
+		}
+		if tc.valid && m.Minor != tc.minor {
+			t.Errorf("ParseMoneyRounded(%q), expected %d minor units, got %d", tc.amount, tc.minor, m.Minor)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	for expected, m := range map[string]Money{
+		"1234.50": NewMoney(123450, "GBP"),
+		"0.05":    NewMoney(5, "GBP"),
+		"-0.05":   NewMoney(-5, "GBP"),
+		"1000":    NewMoney(1000, "JPY"),
+		"1.234":   NewMoney(1234, "KWD"),
+	} {
+		if m.String() != expected {
+			t.Errorf("expected %s, got %s", expected, m.String())
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// summing ten pence a thousand times drifts with float64
+	var amounts []Money
+	for i := 0; i < 1000; i++ {
+		m, _ := ParseMoney("0.10", "GBP")
+		amounts = append(amounts, m)
+	}
+	total, err := SumMoney(amounts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.String() != "100.00" || total.Currency != "GBP" {
+		t.Errorf("expected GBP 100.00, got %s %s", total.Currency, total)
+	}
+
+	diff, err := total.Sub(NewMoney(15000, "GBP"))
+	if err != nil || diff.String() != "-50.00" || !diff.IsNegative() {
+		t.Errorf("expected -50.00, got %s %v", diff, err)
+	}
+	if c, err := total.Cmp(diff); err != nil || c != 1 {
+		t.Errorf("expected total to be greater, got %d %v", c, err)
+	}
+	if _, err = total.Add(NewMoney(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err = NewMoney(1<<62, "GBP").Mul(4); err == nil {
+		t.Error("expected overflow")
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var result struct {
+		Total Money `json:"total"`
+		Empty Money `json:"empty"`
+		Null  Money `json:"null"`
+	}
+	result.Total.Currency = "GBP"
+	if err := json.Unmarshal([]byte(`{"total": 12.5, "empty": "", "null": null}`), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Total.Minor != 1250 || result.Total.Currency != "GBP" || !result.Empty.IsZero() || !result.Null.IsZero() {
+		t.Errorf("unexpected result %+v", result)
+	}
+	b, err := json.Marshal(result.Total)
+	if err != nil || string(b) != `"12.50"` {
+		t.Errorf("expected \"12.50\", got %s %v", b, err)
+	}
+	if err = json.Unmarshal([]byte(`{"total": "NaN"}`), &result); err == nil {
+		t.Error("expected NaN to be rejected")
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	for _, tc := range []struct {
+		m        Money
+		locale   string
+		expected string
+	}{
+		{NewMoney(123450, "GBP"), "en-GB", "£1,234.50"},
+		{NewMoney(-123450, "GBP"), "en", "-£1,234.50"},
+		{NewMoney(123450, "EUR"), "de-DE", "1.234,50\u00a0€"},
+		{NewMoney(123450, "EUR"), "fr_FR", "1\u00a0234,50\u00a0€"},
+		{NewMoney(123450, "EUR"), "nl-NL", "€\u00a01.234,50"},
+		{NewMoney(100000000, "USD"), "xx", "$1,000,000.00"},
+		{NewMoney(5000, "CHF"), "en-GB", "CHF\u00a050.00"},
+		{NewMoney(5, ""), "en-GB", "0.05"},
+	} {
+		if s := tc.m.Format(tc.locale); s != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, s)
+		}
+	}
+}
+
+func TestFundraisingResultsMoney(t *testing.T) {
+	r := FundraisingResults{TotalRaisedOnline: "100.10", TotalRaisedOffline: "20.20", TotalRaisedSMS: "", CurrencyCode: "GBP"}
+	total, err := r.TotalRaisedMoney()
+	if err != nil || total.String() != "120.30" || total.Currency != "GBP" {
+		t.Errorf("expected GBP 120.30, got %s %s %v", total.Currency, total, err)
+	}
+}
+
+func TestHasValidTargetAmount(t *testing.T) {
+	for amount, expected := range map[string]bool{"": true, "999.99": true, "9999": true, "NaN": false, "1e9": false, "-10": false, "0": false, "0.00": false} {
+		if valid := (FundraisingPageForEvent{TargetAmount: amount}).HasValidTargetAmount(); valid != expected {
+			t.Errorf("TargetAmount %q, expected valid %t", amount, expected)
+		}
+	}
+	target := NewMoney(10000, "EUR")
+	if (FundraisingPageForCampaign{Target: &target, CurrencyCode: "GBP"}).HasValidTargetAmount() {
+		t.Error("expected Target in a different currency to the CurrencyCode to be invalid")
+	}
+	zero := NewMoney(0, "GBP")
+	if (FundraisingPageForEvent{Target: &zero, CurrencyCode: "GBP"}).HasValidTargetAmount() {
+		t.Error("expected a zero Target to be invalid")
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// OfflineDonationValidationService defines the validation methods requiring calls to the JustGiving API.
+//
+// For an implementation see justin.Service
+type OfflineDonationValidationService interface {
+	IsValidCurrencyCode(currencyCode string) (bool, error)
+}
+
+// OfflineDonation represents a donation collected offline (e.g. cash or cheque) and recorded against a JustGiving fundraising page
+type OfflineDonation struct {
+	ID uint `json:"id"`
+
+	// Amount of the donation expressed as a valid currency amount e.g. "999.99" or "9999"
+	Amount string `json:"amount"`
+
+	CurrencyCode string `json:"currencyCode"`
+
+	DonorName string `json:"donorName"`
+
+	Message string `json:"message"`
+
+	DonationDate string `json:"donationDate"`
+}
+
+// ParseDonationDate attempts to convert the DonationDate returned by JustGiving to a Time
+func (d OfflineDonation) ParseDonationDate() (time.Time, error) {
+	return ParseDate(d.DonationDate)
+}
+
+// HasValidCurrencyCode checks the CurrencyCode is in the published JustGiving currency code list
+func (d OfflineDonation) HasValidCurrencyCode(vs OfflineDonationValidationService) (bool, error) {
+	return vs.IsValidCurrencyCode(d.CurrencyCode)
+}
+
+// AmountMoney returns the Amount as Money in the CurrencyCode of the donation
+func (d OfflineDonation) AmountMoney() (Money, error) {
+	return ParseMoney(d.Amount, d.CurrencyCode)
+}
+
+// HasValidAmount checks the Amount is a positive currency amount, with no more decimal places than the minor unit of the CurrencyCode
+func (d OfflineDonation) HasValidAmount() bool {
+	m, err := d.AmountMoney()
+	return err == nil && m.Minor > 0
+}
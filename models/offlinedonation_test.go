@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestOfflineDonationHasValidAmount(t *testing.T) {
+	for _, tc := range []struct {
+		amount   string
+		currency string
+		valid    bool
+	}{
+		{"10.50", "GBP", true},
+		{"1,234.50", "GBP", true},
+		{"1000", "JPY", true},
+		{"1.234", "KWD", true},
+		{"10.501", "GBP", false},
+		{"10.5", "JPY", false},
+		{"0.00", "GBP", false},
+		{"-5.00", "GBP", false},
+		{"NaN", "GBP", false},
+		{"", "GBP", false},
+	} {
+		if valid := (OfflineDonation{Amount: tc.amount, CurrencyCode: tc.currency}).HasValidAmount(); valid != tc.valid {
+			t.Errorf("%s %s, expected valid %t", tc.amount, tc.currency, tc.valid)
+		}
+	}
+}
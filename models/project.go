@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Project represents a JustGiving crowdfunding project
+type Project struct {
+	ID           uint     `json:"projectId"`
+	ShortName    string   `json:"pageShortName"`
+	Title        string   `json:"title"`
+	Summary      string   `json:"summary"`
+	Story        string   `json:"story"`
+	OwnerName    string   `json:"ownerName"`
+	Status       string   `json:"status"`
+	CurrencyCode string   `json:"currencyCode"`
+	TargetAmount string   `json:"targetAmount"`
+	StartDate    string   `json:"activeDate"`
+	TargetDate   string   `json:"targetDate"`
+	Rewards      []Reward `json:"rewards"`
+	ProjectTotals
+}
+
+// ParseStartDate attempts to convert the StartDate returned by JustGiving to a Time
+func (p Project) ParseStartDate() (time.Time, error) {
+	return ParseDate(p.StartDate)
+}
+
+// ParseTargetDate attempts to convert the TargetDate returned by JustGiving to a Time
+func (p Project) ParseTargetDate() (time.Time, error) {
+	return ParseDate(p.TargetDate)
+}
+
+// ProjectTotals contains the current funding totals of a crowdfunding project as provided by JustGiving
+type ProjectTotals struct {
+	AmountRaised       string `json:"amountRaised"`
+	AmountPledged      string `json:"amountPledged"`
+	PercentageOfTarget string `json:"percentageOfTarget"`
+	NumberOfSupporters uint   `json:"numberOfSupporters"`
+}
+
+// Reward represents a reward offered to the supporters of a crowdfunding project
+type Reward struct {
+	ID           uint   `json:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Amount       string `json:"amount"`
+	Limit        uint   `json:"limit"`
+	Remaining    uint   `json:"remaining"`
+	DeliveryDate string `json:"deliveryDate"`
+}
+
+// ParseDeliveryDate attempts to convert the DeliveryDate returned by JustGiving to a Time
+func (r Reward) ParseDeliveryDate() (time.Time, error) {
+	return ParseDate(r.DeliveryDate)
+}
+
+// ProjectSupporter represents a pledge made by a supporter of a crowdfunding project
+type ProjectSupporter struct {
+	Name         string `json:"name"`
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currencyCode"`
+	Message      string `json:"message"`
+	RewardID     uint   `json:"rewardId"`
+	PledgeDate   string `json:"pledgeDate"`
+}
+
+// ParsePledgeDate attempts to convert the PledgeDate returned by JustGiving to a Time
+func (s ProjectSupporter) ParsePledgeDate() (time.Time, error) {
+	return ParseDate(s.PledgeDate)
+}
@@ -0,0 +1,14 @@
+package models
+
+// Country represents a country in the published JustGiving countries list
+type Country struct {
+	Name string `json:"name"`
+	Code string `json:"countryCode"`
+}
+
+// Currency represents a currency in the published JustGiving currency code list
+type Currency struct {
+	Code        string `json:"currencyCode"`
+	Symbol      string `json:"currencySymbol"`
+	Description string `json:"description"`
+}
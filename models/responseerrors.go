@@ -0,0 +1,167 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// ResponseError is an error reported by JustGiving in the body of a failed response, e.g. {"id": "PageShortNameAlreadyExists", "desc": "..."}
+type ResponseError struct {
+	ID   string `json:"id"`
+	Desc string `json:"desc"`
+}
+
+// ParseResponseErrors decodes the errors reported in the body of a failed JustGiving response
+//
+// Both a list of errors and an object with an "errors" list are accepted, nil is returned for any other body.
+func ParseResponseErrors(body string) []ResponseError {
+	var results []ResponseError
+	if err := json.Unmarshal([]byte(body), &results); err == nil {
+		return results
+	}
+	var wrapped struct {
+		Errors []ResponseError `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(body), &wrapped); err == nil {
+		return wrapped.Errors
+	}
+	return nil
+}
+
+// accountErrorFields map the (normalised) prefixes of JustGiving error ids onto Account fields
+var accountErrorFields = map[string]string{
+	"title":             "Title",
+	"firstname":         "FirstName",
+	"lastname":          "LastName",
+	"email":             "Email",
+	"password":          "Password",
+	"addressline1":      "AddressLine1",
+	"line1":             "AddressLine1",
+	"addressline2":      "AddressLine2",
+	"line2":             "AddressLine2",
+	"county":            "County",
+	"addresscounty":     "County",
+	"countyorstate":     "County",
+	"townorcity":        "TownOrCity",
+	"addresstownorcity": "TownOrCity",
+	"postcode":          "Postcode",
+	"addresspostcode":   "Postcode",
+	"country":           "Country",
+	"addresscountry":    "Country",
+}
+
+// eventPageErrorFields map the (normalised) prefixes of JustGiving error ids onto FundraisingPageForEvent fields
+var eventPageErrorFields = map[string]string{
+	"charity":          "CharityID",
+	"charityid":        "CharityID",
+	"event":            "EventID",
+	"eventid":          "EventID",
+	"pageshortname":    "PageShortName",
+	"shortname":        "PageShortName",
+	"pagetitle":        "PageTitle",
+	"pagestory":        "PageStory",
+	"story":            "PageStory",
+	"image":            "Images",
+	"customcode":       "CustomCodes",
+	"targetamount":     "TargetAmount",
+	"target":           "TargetAmount",
+	"currency":         "CurrencyCode",
+	"charityfunded":    "CharityFunded",
+	"justgivingoptin":  "JustGivingOptIn",
+	"charityoptin":     "CharityOptIn",
+	"team":             "TeamID",
+	"teamid":           "TeamID",
+	"activitytype":     "ActivityType",
+	"eventname":        "EventName",
+	"eventdate":        "EventDate",
+	"expirydate":       "ExpiryDate",
+	"reference":        "Reference",
+	"rememberedperson": "RememberedPerson",
+	"relationship":     "RememberedPersonRelationship",
+	"tag":              "Tags",
+}
+
+// campaignPageErrorFields map the (normalised) prefixes of JustGiving error ids onto FundraisingPageForCampaign fields
+var campaignPageErrorFields = map[string]string{
+	"campaign":        "CampaignID",
+	"campaignguid":    "CampaignID",
+	"charity":         "CharityID",
+	"charityid":       "CharityID",
+	"pageshortname":   "PageShortName",
+	"shortname":       "PageShortName",
+	"pagetitle":       "PageTitle",
+	"pagestory":       "PageStory",
+	"story":           "PageStory",
+	"image":           "Images",
+	"customcode":      "CustomCodes",
+	"targetamount":    "TargetAmount",
+	"target":          "TargetAmount",
+	"currency":        "CurrencyCode",
+	"charityfunded":   "CharityFunded",
+	"justgivingoptin": "JustGivingOptIn",
+	"charityoptin":    "CharityOptIn",
+	"team":            "TeamID",
+	"teamid":          "TeamID",
+}
+
+// FieldErrors maps the errors reported by JustGiving for an account registration onto the Account fields
+//
+// The Code of each ValidationError is the JustGiving error id, errors which don't match a field have an empty Field.
+func (acc Account) FieldErrors(errs []ResponseError) ValidationErrors {
+	return fieldErrors(errs, accountErrorFields)
+}
+
+// FieldErrors maps the errors reported by JustGiving for a page registration onto the FundraisingPageForEvent fields
+//
+// The Code of each ValidationError is the JustGiving error id, errors which don't match a field have an empty Field.
+func (fp FundraisingPageForEvent) FieldErrors(errs []ResponseError) ValidationErrors {
+	return fieldErrors(errs, eventPageErrorFields)
+}
+
+// FieldErrors maps the errors reported by JustGiving for a page registration onto the FundraisingPageForCampaign fields
+//
+// The Code of each ValidationError is the JustGiving error id, errors which don't match a field have an empty Field.
+func (fp FundraisingPageForCampaign) FieldErrors(errs []ResponseError) ValidationErrors {
+	return fieldErrors(errs, campaignPageErrorFields)
+}
+
+func fieldErrors(errs []ResponseError, fields map[string]string) ValidationErrors {
+	var ve ValidationErrors
+	for _, e := range errs {
+		message := e.Desc
+		if message == "" {
+			message = e.ID
+		}
+		ve.add(errorField(e.ID, fields), e.ID, message)
+	}
+	return ve
+}
+
+// errorField returns the field matching the longest prefix of the error id, e.g. "PageShortNameAlreadyExists" is the PageShortName
+func errorField(id string, fields map[string]string) string {
+	normalised := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, id)
+	var prefix string
+	for p := range fields {
+		if len(p) > len(prefix) && strings.HasPrefix(normalised, p) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return ""
+	}
+	field := fields[prefix]
+	// custom codes are numbered e.g. "CustomCode3TooLong"
+	if field == "CustomCodes" {
+		rest := normalised[len(prefix):]
+		if len(rest) > 0 && rest[0] >= '1' && rest[0] <= '0'+MaxCustomCodes {
+			field += "[" + rest[:1] + "]"
+		}
+	}
+	return field
+}
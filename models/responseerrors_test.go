@@ -0,0 +1,71 @@
+package models
+
+import "testing"
+
+func TestParseResponseErrors(t *testing.T) {
+	for body, expected := range map[string]int{
+		`[{"id": "FirstNameNotSpecified", "desc": "First name is required"}]`: 1,
+		`{"errors": [{"id": "a"}, {"id": "b"}]}`:                              2,
+		`<html>Bad Request</html>`:                                            0,
+		``:                                                                    0,
+	} {
+		if errs := ParseResponseErrors(body); len(errs) != expected {
+			t.Errorf("%s, expected %d errors, got %v", body, expected, errs)
+		}
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	for id, expected := range map[string]string{
+		"FirstNameNotSpecified":     "FirstName",
+		"EmailAddressInUse":         "Email",
+		"address.postcodeOrZipcode": "Postcode",
+		"CountyOrStateTooLong":      "County",
+		"CountryNotRecognised":      "Country",
+		"AcceptTermsAndConditions":  "",
+	} {
+		ve := Account{}.FieldErrors([]ResponseError{{ID: id}})
+		if len(ve) != 1 || ve[0].Field != expected || ve[0].Code != id || ve[0].Message != id {
+			t.Errorf("%s, expected %q, got %+v", id, expected, ve)
+		}
+	}
+	for id, expected := range map[string]string{
+		"PageShortNameAlreadyExists": "PageShortName",
+		"charityId":                  "CharityID",
+		"CharityOptInRequired":       "CharityOptIn",
+		"CurrencyCodeInvalid":        "CurrencyCode",
+		"CustomCode3TooLong":         "CustomCodes[3]",
+		"ImageUrlInvalid":            "Images",
+	} {
+		ve := FundraisingPageForEvent{}.FieldErrors([]ResponseError{{ID: id, Desc: "desc"}})
+		if len(ve) != 1 || ve[0].Field != expected || ve[0].Message != "desc" {
+			t.Errorf("%s, expected %q, got %+v", id, expected, ve)
+		}
+	}
+	// each page type only maps errors onto its own fields
+	for id, expected := range map[string]string{
+		"EventIdInvalid":        "EventID",
+		"EventNameTooLong":      "EventName",
+		"CampaignGuidNotFound":  "",
+		"ReferenceTooLong":      "Reference",
+		"PageShortNameTooShort": "PageShortName",
+	} {
+		ve := FundraisingPageForEvent{}.FieldErrors([]ResponseError{{ID: id}})
+		if len(ve) != 1 || ve[0].Field != expected {
+			t.Errorf("event page %s, expected %q, got %+v", id, expected, ve)
+		}
+	}
+	for id, expected := range map[string]string{
+		"CampaignGuidNotFound":  "CampaignID",
+		"EventIdInvalid":        "",
+		"EventNameTooLong":      "",
+		"ReferenceTooLong":      "",
+		"PageShortNameTooShort": "PageShortName",
+		"CustomCode2TooLong":    "CustomCodes[2]",
+	} {
+		ve := FundraisingPageForCampaign{}.FieldErrors([]ResponseError{{ID: id}})
+		if len(ve) != 1 || ve[0].Field != expected {
+			t.Errorf("campaign page %s, expected %q, got %+v", id, expected, ve)
+		}
+	}
+}
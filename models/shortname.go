@@ -0,0 +1,81 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accents map accented letters onto their plain equivalents
+var accents = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a",
+	'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'œ': "oe",
+	'ř': "r",
+	'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss",
+	'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// NormalisePageShortName converts a name to a valid fundraising page short name, e.g. "Zoë's London Marathon!" is "zoes-london-marathon"
+//
+// Accents are removed, letters are lowercased, spaces and punctuation between words become a hyphen, other characters are removed and the
+// result is truncated to MaxPageShortNameLength.
+func NormalisePageShortName(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '\'' || r == '’':
+			// apostrophes join words e.g. "zoe's" is "zoes"
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		case accents[r] != "":
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteString(accents[r])
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hyphen = true
+		}
+	}
+	return truncatePageShortName(b.String(), MaxPageShortNameLength)
+}
+
+// truncatePageShortName truncates a short name to at most max characters, without leaving a trailing hyphen
+func truncatePageShortName(name string, max int) string {
+	if max < 0 {
+		max = 0
+	}
+	if len(name) > max {
+		name = name[:max]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// PageShortNameWithSuffix returns the short name with a hyphenated suffix, truncating the name so the result is at most MaxPageShortNameLength
+func PageShortNameWithSuffix(name string, suffix string) string {
+	suffix = NormalisePageShortName(suffix)
+	if suffix == "" {
+		return name
+	}
+	name = truncatePageShortName(name, MaxPageShortNameLength-len(suffix)-1)
+	if name == "" {
+		return suffix
+	}
+	return name + "-" + suffix
+}
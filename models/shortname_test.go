@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalisePageShortName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Zoë's London Marathon!":  "zoes-london-marathon",
+		"  Ünïcödé   Façade  ":    "unicode-facade",
+		"Straße_2024":             "strasse-2024",
+		"Œuvre & Æsop":            "oeuvre-aesop",
+		"already-valid-123":       "already-valid-123",
+		"---":                     "",
+		"日本":                      "",
+		strings.Repeat("ab ", 30): strings.TrimRight(strings.Repeat("ab-", 17), "-"),
+	} {
+		if s := NormalisePageShortName(name); s != expected {
+			t.Errorf("%q, expected %q, got %q", name, expected, s)
+		}
+	}
+}
+
+func TestPageShortNameWithSuffix(t *testing.T) {
+	long := strings.Repeat("a", MaxPageShortNameLength)
+	for _, tc := range []struct {
+		name, suffix, expected string
+	}{
+		{"zoe", "London Marathon", "zoe-london-marathon"},
+		{"zoe", "", "zoe"},
+		{long, "2024", strings.Repeat("a", MaxPageShortNameLength-5) + "-2024"},
+		{"", "2024", "2024"},
+	} {
+		s := PageShortNameWithSuffix(tc.name, tc.suffix)
+		if s != tc.expected || len(s) > MaxPageShortNameLength {
+			t.Errorf("%q %q, expected %q, got %q", tc.name, tc.suffix, tc.expected, s)
+		}
+	}
+}
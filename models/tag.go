@@ -0,0 +1,7 @@
+package models
+
+// Tag is a custom tag on a fundraising page, as defined for the charity or event
+type Tag struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
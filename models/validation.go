@@ -0,0 +1,255 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Validation error codes
+const (
+	ValidationRequired          = "required"
+	ValidationTooLong           = "too_long"
+	ValidationTooShort          = "too_short"
+	ValidationInvalidCharacters = "invalid_characters"
+	ValidationInvalidFormat     = "invalid_format"
+	ValidationInvalidScheme     = "invalid_scheme"
+	ValidationInvalidCountry    = "invalid_country"
+	ValidationInvalidCurrency   = "invalid_currency"
+	ValidationInvalidAmount     = "invalid_amount"
+	ValidationWeakPassword      = "weak_password"
+	ValidationMultipleDefaults  = "multiple_defaults"
+	ValidationInvalidDate       = "invalid_date"
+)
+
+// Limits checked by Validate
+const (
+	MaxPageShortNameLength = 50
+	MaxPageTitleLength     = 255
+	MaxPageStoryLength     = 10000
+	MaxCustomCodeLength    = 50
+	MaxImageCaptionLength  = 200
+	MaxNameLength          = 50
+	MaxTownLength          = 100
+	MinPasswordLength      = 8
+)
+
+// ValidationError describes a problem with a single field, Field is its path e.g. "Images[0].URL"
+type ValidationError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors aggregates the problems found by Validate
+type ValidationErrors []ValidationError
+
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, len(ve))
+	for i, e := range ve {
+		messages[i] = e.Error()
+	}
+	return "invalid " + strings.Join(messages, "; ")
+}
+
+// Field returns the problems found with the specified field
+func (ve ValidationErrors) Field(field string) ValidationErrors {
+	var results ValidationErrors
+	for _, e := range ve {
+		if e.Field == field {
+			results = append(results, e)
+		}
+	}
+	return results
+}
+
+func (ve *ValidationErrors) add(field string, code string, message string) {
+	*ve = append(*ve, ValidationError{Field: field, Code: code, Message: message})
+}
+
+// err returns nil if there are no problems, so callers can return it as an error
+func (ve ValidationErrors) err() error {
+	if len(ve) == 0 {
+		return nil
+	}
+	return ve
+}
+
+func (ve *ValidationErrors) required(field string, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		ve.add(field, ValidationRequired, "is required")
+		return false
+	}
+	return true
+}
+
+func (ve *ValidationErrors) maxLength(field string, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		ve.add(field, ValidationTooLong, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+var pageShortNameChars = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// postcodeFormats are the postcode formats of countries in the published JustGiving Countries list, keyed by lowercase name
+var postcodeFormats = map[string]*regexp.Regexp{
+	"united kingdom": regexp.MustCompile(`(?i)^([A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2}|GIR ?0AA)$`),
+	"united states":  regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`),
+	"canada":         regexp.MustCompile(`(?i)^[A-Z][0-9][A-Z] ?[0-9][A-Z][0-9]$`),
+	"australia":      regexp.MustCompile(`^[0-9]{4}$`),
+	"new zealand":    regexp.MustCompile(`^[0-9]{4}$`),
+	"ireland":        regexp.MustCompile(`(?i)^[A-Z][0-9][0-9W] ?[A-Z0-9]{4}$`),
+	"germany":        regexp.MustCompile(`^[0-9]{5}$`),
+	"france":         regexp.MustCompile(`^[0-9]{5}$`),
+	"spain":          regexp.MustCompile(`^[0-9]{5}$`),
+	"italy":          regexp.MustCompile(`^[0-9]{5}$`),
+	"netherlands":    regexp.MustCompile(`(?i)^[0-9]{4} ?[A-Z]{2}$`),
+}
+
+// Validate checks the Account against the JustGiving registration rules before it is sent, returning ValidationErrors for any problems found.
+//
+// vs is optional, when provided the Country is also checked against the published JustGiving Countries list.
+func (acc Account) Validate(vs AccountValidationService) error {
+	var ve ValidationErrors
+	if ve.required("Title", acc.Title) {
+		ve.maxLength("Title", acc.Title, MaxNameLength)
+	}
+	if ve.required("FirstName", acc.FirstName) {
+		ve.maxLength("FirstName", acc.FirstName, MaxNameLength)
+	}
+	if ve.required("LastName", acc.LastName) {
+		ve.maxLength("LastName", acc.LastName, MaxNameLength)
+	}
+	if ve.required("Email", acc.Email.Address) {
+		if !validEmail(acc.Email.Address) {
+			ve.add("Email", ValidationInvalidFormat, "must be a valid email address")
+		}
+	}
+	if ve.required("Password", acc.Password) {
+		validatePassword(&ve, acc.Password)
+	}
+	ve.required("AddressLine1", acc.AddressLine1)
+	ve.required("TownOrCity", acc.TownOrCity)
+	if ve.required("Postcode", acc.Postcode) {
+		if format, ok := postcodeFormats[strings.ToLower(strings.TrimSpace(acc.Country))]; ok && !format.MatchString(strings.TrimSpace(acc.Postcode)) {
+			ve.add("Postcode", ValidationInvalidFormat, fmt.Sprintf("must be a valid postcode for %s", acc.Country))
+		}
+	}
+	if ve.required("Country", acc.Country) && vs != nil {
+		valid, err := acc.HasValidCountry(vs)
+		if err != nil {
+			return fmt.Errorf("error running Country validation %w", err)
+		}
+		if !valid {
+			ve.add("Country", ValidationInvalidCountry, "must be in the JustGiving countries list")
+		}
+	}
+	return ve.err()
+}
+
+// validEmail checks the address is a plain email address with a domain, e.g. rob@golang.org
+func validEmail(address string) bool {
+	addr, err := mail.ParseAddress(address)
+	if err != nil || addr.Address != address {
+		return false
+	}
+	domain := address[strings.LastIndex(address, "@")+1:]
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+func validatePassword(ve *ValidationErrors, password string) {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		ve.add("Password", ValidationTooShort, fmt.Sprintf("must be at least %d characters", MinPasswordLength))
+		return
+	}
+	var letter, digit bool
+	for _, r := range password {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	if !letter || !digit {
+		ve.add("Password", ValidationWeakPassword, "must contain letters and numbers")
+	}
+}
+
+// Validate checks the FundraisingPageForEvent against the JustGiving registration rules before it is sent, returning ValidationErrors for any problems found.
+//
+// vs is optional, when provided the currency is also checked against the published JustGiving currency code list.
+func (fp FundraisingPageForEvent) Validate(vs FundraisingPageForEventValidationService) error {
+	var ve ValidationErrors
+	if fp.CharityID == 0 {
+		ve.add("CharityID", ValidationRequired, "is required")
+	}
+	if fp.EventID == 0 {
+		ve.add("EventID", ValidationRequired, "is required")
+	}
+	validatePage(&ve, fp.PageShortName, fp.PageTitle, fp.PageStory, fp.CustomCodes, fp.Images)
+	validateInMemory(&ve, fp.ActivityType, fp.RememberedPerson, fp.RememberedPersonRelationship)
+	if !fp.HasValidTargetAmount() {
+		ve.add("TargetAmount", ValidationInvalidAmount, "must be a positive amount in the page currency")
+	}
+	if vs != nil && (fp.CurrencyCode != "" || (fp.Target != nil && fp.Target.Currency != "")) {
+		valid, err := fp.HasValidCurrencyCode(vs)
+		if err != nil {
+			return fmt.Errorf("error running CurrencyCode validation %w", err)
+		}
+		if !valid {
+			ve.add("CurrencyCode", ValidationInvalidCurrency, "must be in the JustGiving currency code list")
+		}
+	}
+	return ve.err()
+}
+
+// validatePage checks the fields common to fundraising pages
+func validatePage(ve *ValidationErrors, shortName string, title string, story string, customCodes CustomCodes, images []Image) {
+	if ve.required("PageShortName", shortName) {
+		ve.maxLength("PageShortName", shortName, MaxPageShortNameLength)
+		if !pageShortNameChars.MatchString(shortName) {
+			ve.add("PageShortName", ValidationInvalidCharacters, "must only contain letters, numbers and hyphens")
+		}
+	}
+	if ve.required("PageTitle", title) {
+		ve.maxLength("PageTitle", title, MaxPageTitleLength)
+	}
+	ve.maxLength("PageStory", story, MaxPageStoryLength)
+	// validate the codes in order so the errors are reported in the same order every time
+	numbers := make([]int, 0, len(customCodes))
+	for n := range customCodes {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	for _, n := range numbers {
+		code := customCodes[n]
+		field := fmt.Sprintf("CustomCodes[%d]", n)
+		if n < 1 || n > MaxCustomCodes {
+			ve.add(field, ValidationInvalidFormat, fmt.Sprintf("must be numbered 1 to %d", MaxCustomCodes))
+			continue
+		}
+		ve.maxLength(field, code, MaxCustomCodeLength)
+	}
+	defaults := 0
+	for i, img := range images {
+		if img.URL.Scheme != "http" && img.URL.Scheme != "https" {
+			ve.add(fmt.Sprintf("Images[%d].URL", i), ValidationInvalidScheme, "must be an http or https URL")
+		} else if img.URL.Host == "" {
+			ve.add(fmt.Sprintf("Images[%d].URL", i), ValidationInvalidFormat, "must include a host")
+		}
+		ve.maxLength(fmt.Sprintf("Images[%d].Caption", i), img.Caption, MaxImageCaptionLength)
+		if img.IsDefault {
+			if defaults++; defaults > 1 {
+				ve.add(fmt.Sprintf("Images[%d].IsDefault", i), ValidationMultipleDefaults, "only one image can be the default")
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type testValidationService struct {
+	err error
+}
+
+func (vs testValidationService) IsValidCountry(name string) (bool, error) {
+	return name == "United Kingdom", vs.err
+}
+
+func (vs testValidationService) IsValidCurrencyCode(code string) (bool, error) {
+	return code == "GBP", vs.err
+}
+
+func validationCodes(t *testing.T, err error) map[string]string {
+	var ve ValidationErrors
+	if err != nil && !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	codes := make(map[string]string)
+	for _, e := range ve {
+		codes[e.Field] = e.Code
+	}
+	return codes
+}
+
+func TestAccountValidate(t *testing.T) {
+	acc := Account{
+		Title:        "Mr",
+		FirstName:    "Rob",
+		LastName:     "Pike",
+		Email:        mail.Address{Address: "rob@golang.org"},
+		Password:     "secret123",
+		AddressLine1: "1 Main Street",
+		TownOrCity:   "London",
+		Postcode:     "SW1A 1AA",
+		Country:      "United Kingdom",
+	}
+	if err := acc.Validate(testValidationService{}); err != nil {
+		t.Fatalf("expected valid account, got %v", err)
+	}
+
+	acc.FirstName = ""
+	acc.Email = mail.Address{Address: "rob@localhost"}
+	acc.Password = "secretsecret"
+	acc.Postcode = "12345"
+	acc.Country = "Narnia"
+	codes := validationCodes(t, acc.Validate(testValidationService{}))
+	expected := map[string]string{
+		"FirstName": ValidationRequired,
+		"Email":     ValidationInvalidFormat,
+		"Password":  ValidationWeakPassword,
+		"Country":   ValidationInvalidCountry,
+	}
+	if len(codes) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, codes)
+	}
+	for field, code := range expected {
+		if codes[field] != code {
+			t.Errorf("expected %s %s, got %s", field, code, codes[field])
+		}
+	}
+
+	acc.Country = "United Kingdom"
+	if codes = validationCodes(t, acc.Validate(nil)); codes["Postcode"] != ValidationInvalidFormat {
+		t.Errorf("expected invalid postcode, got %v", codes)
+	}
+
+	remoteErr := errors.New("unavailable")
+	if err := acc.Validate(testValidationService{err: remoteErr}); !errors.Is(err, remoteErr) {
+		t.Errorf("expected remote error, got %v", err)
+	}
+}
+
+func TestFundraisingPageForEventValidate(t *testing.T) {
+	img, _ := url.Parse("https://example.com/image.jpg")
+	page := FundraisingPageForEvent{
+		CharityID:     1,
+		EventID:       2,
+		PageShortName: "my-page-1",
+		PageTitle:     "My Page",
+		TargetAmount:  "100.00",
+		CurrencyCode:  "GBP",
+		Images:        []Image{{Caption: "Me", URL: *img}},
+	}
+	if err := page.Validate(testValidationService{}); err != nil {
+		t.Fatalf("expected valid page, got %v", err)
+	}
+
+	ftp, _ := url.Parse("ftp://example.com/image.jpg")
+	page.EventID = 0
+	page.PageShortName = "my page!"
+	page.PageTitle = strings.Repeat("a", MaxPageTitleLength+1)
+	page.CustomCodes = CustomCodes{1: "", 3: strings.Repeat("a", MaxCustomCodeLength+1), 7: "a"}
+	page.Images = append(page.Images, Image{URL: *ftp})
+	page.Images[0].IsDefault, page.Images[1].IsDefault = true, true
+	page.TargetAmount = "NaN"
+	page.CurrencyCode = "XYZ"
+	err := page.Validate(testValidationService{})
+	codes := validationCodes(t, err)
+	expected := map[string]string{
+		"EventID":             ValidationRequired,
+		"PageShortName":       ValidationInvalidCharacters,
+		"PageTitle":           ValidationTooLong,
+		"CustomCodes[3]":      ValidationTooLong,
+		"CustomCodes[7]":      ValidationInvalidFormat,
+		"Images[1].URL":       ValidationInvalidScheme,
+		"Images[1].IsDefault": ValidationMultipleDefaults,
+		"TargetAmount":        ValidationInvalidAmount,
+		"CurrencyCode":        ValidationInvalidCurrency,
+	}
+	if len(codes) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, codes)
+	}
+	for field, code := range expected {
+		if codes[field] != code {
+			t.Errorf("expected %s %s, got %s", field, code, codes[field])
+		}
+	}
+	var ve ValidationErrors
+	errors.As(err, &ve)
+	if len(ve.Field("PageShortName")) != 1 {
+		t.Errorf("expected a single PageShortName error, got %v", ve.Field("PageShortName"))
+	}
+
+	// the custom codes are reported in order, so the error is the same every time
+	page = FundraisingPageForEvent{CharityID: 1, EventID: 2, PageShortName: "page", PageTitle: "Page"}
+	page.CustomCodes = CustomCodes{9: "a", 2: strings.Repeat("a", MaxCustomCodeLength+1), 0: "a", 7: "a", 5: strings.Repeat("b", MaxCustomCodeLength+1)}
+	first := page.Validate(nil).Error()
+	for i := 0; i < 20; i++ {
+		if err = page.Validate(nil); err.Error() != first {
+			t.Fatalf("expected the same error every time, got %q and %q", first, err)
+		}
+	}
+	ve = nil
+	errors.As(page.Validate(nil), &ve)
+	var fields []string
+	for _, e := range ve {
+		fields = append(fields, e.Field)
+	}
+	if strings.Join(fields, ",") != "CustomCodes[0],CustomCodes[2],CustomCodes[5],CustomCodes[7],CustomCodes[9]" {
+		t.Errorf("expected the custom codes in order, got %v", fields)
+	}
+}
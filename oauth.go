@@ -0,0 +1,323 @@
+package justin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
+)
+
+const (
+	sandboxIdentityBasePath = "https://identity.sandbox.justgiving.com"
+	liveIdentityBasePath    = "https://identity.justgiving.com"
+
+	// expiryDelta is subtracted from a Token's expiry so it is refreshed before JustGiving rejects it
+	expiryDelta = 10 * time.Second
+)
+
+// OAuthContext contains settings for creating a justin Service that authenticates users with the JustGiving OAuth2 authorization code flow.
+//
+// ClientID and ClientSecret are the credentials of the JustGiving application, ClientID is usually the same as the APIKey.
+//
+// RedirectURL is where JustGiving sends the user after they authorize the application.
+//
+// Scopes are the requested OAuth2 scopes, e.g. "openid", "account", "fundraise" and "offline_access" (to receive a refresh token).
+//
+// IdentityBasePath is an optional override of the JustGiving identity server, if not provided it is based on the Env.
+//
+// TokenStore is an optional store used by TokenFor, if not provided tokens are kept in memory.
+type OAuthContext struct {
+	ClientID         string
+	ClientSecret     string
+	RedirectURL      string
+	Scopes           []string
+	IdentityBasePath string
+	TokenStore       TokenStore
+}
+
+// Token is an OAuth2 token issued by the JustGiving identity server
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Valid reports whether the Token has an access token which has not expired
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// Authorize sets the Bearer Authentication header using the AccessToken, so a Token can be used as Credentials
+func (t *Token) Authorize(req *http.Request) error {
+	if t == nil || t.AccessToken == "" {
+		return errors.New("missing access token")
+	}
+	req.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	return nil
+}
+
+// TokenStore provides an interface for persisting OAuth2 tokens between requests, keyed by an identifier of the caller's choosing (e.g. a user id)
+type TokenStore interface {
+	Token(key string) (*Token, error)
+	SetToken(key string, token *Token) error
+}
+
+// NewMemoryTokenStore returns a TokenStore which keeps tokens in memory
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{tokens: make(map[string]*Token)}
+}
+
+type memoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]*Token
+}
+
+func (s *memoryTokenStore) Token(key string) (*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tokens[key], nil
+}
+
+func (s *memoryTokenStore) SetToken(key string, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = token
+	return nil
+}
+
+// CreateWithOAuth instantiates the Service using an APIKey for authentication, with user authentication through the JustGiving OAuth2 authorization code flow
+func CreateWithOAuth(api APIKeyContext, oauth OAuthContext) (svc *Service, err error) {
+	if oauth.ClientID == "" {
+		return nil, errors.New("missing OAuth ClientID")
+	}
+	svc, err = CreateWithAPIKey(api)
+	if err != nil {
+		return nil, err
+	}
+	if oauth.IdentityBasePath == "" {
+		switch api.Env {
+		case Sandbox:
+			oauth.IdentityBasePath = sandboxIdentityBasePath
+		case Live:
+			oauth.IdentityBasePath = liveIdentityBasePath
+		}
+	}
+	if oauth.TokenStore == nil {
+		oauth.TokenStore = NewMemoryTokenStore()
+	}
+	svc.oauth = &oauth
+	svc.refreshes = &keyedMutex{}
+	return svc, nil
+}
+
+// AuthorizeURL returns the JustGiving URL to send a user to in order to authorize this application, state is returned unchanged to the RedirectURL
+func (svc *Service) AuthorizeURL(state string) (string, error) {
+	if svc.oauth == nil {
+		return "", errors.New("service not created with OAuth")
+	}
+	v := url.Values{}
+	v.Set("client_id", svc.oauth.ClientID)
+	v.Set("response_type", "code")
+	v.Set("redirect_uri", svc.oauth.RedirectURL)
+	v.Set("scope", strings.Join(svc.oauth.Scopes, " "))
+	v.Set("state", state)
+	return svc.oauth.IdentityBasePath + "/connect/authorize?" + v.Encode(), nil
+}
+
+// ExchangeCode exchanges the authorization code returned to the RedirectURL for a Token
+func (svc *Service) ExchangeCode(code string) (*Token, error) {
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	if svc.oauth != nil {
+		v.Set("redirect_uri", svc.oauth.RedirectURL)
+	}
+	return svc.requestToken("ExchangeCode", v)
+}
+
+// RefreshToken uses the refresh token of the supplied Token to obtain a new Token
+func (svc *Service) RefreshToken(token *Token) (*Token, error) {
+	if token == nil || token.RefreshToken == "" {
+		return nil, errors.New("missing refresh token")
+	}
+	v := url.Values{}
+	v.Set("grant_type", "refresh_token")
+	v.Set("refresh_token", token.RefreshToken)
+	result, err := svc.requestToken("RefreshToken", v)
+	if err != nil {
+		return nil, err
+	}
+	// the identity server may not issue a new refresh token
+	if result.RefreshToken == "" {
+		result.RefreshToken = token.RefreshToken
+	}
+	return result, nil
+}
+
+// SetToken saves the Token in the TokenStore under the specified key, typically after calling ExchangeCode
+func (svc *Service) SetToken(key string, token *Token) error {
+	if svc.oauth == nil {
+		return errors.New("service not created with OAuth")
+	}
+	return svc.oauth.TokenStore.SetToken(key, token)
+}
+
+// TokenFor returns a valid Token from the TokenStore for the specified key, refreshing and saving it if it has expired
+//
+// Refreshes are serialized per key, so concurrent callers share a single refresh (refresh tokens may only be usable once).
+func (svc *Service) TokenFor(key string) (*Token, error) {
+	if svc.oauth == nil {
+		return nil, errors.New("service not created with OAuth")
+	}
+	token, err := svc.storedToken(key)
+	if err != nil || token.Valid() {
+		return token, err
+	}
+
+	unlock := svc.refreshes.lock(key)
+	defer unlock()
+	// another caller may have refreshed the token while waiting for the lock
+	if token, err = svc.storedToken(key); err != nil || token.Valid() {
+		return token, err
+	}
+	token, err = svc.RefreshToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err = svc.oauth.TokenStore.SetToken(key, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (svc *Service) storedToken(key string) (*Token, error) {
+	token, err := svc.oauth.TokenStore.Token(key)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, fmt.Errorf("no token stored for %s", key)
+	}
+	return token, nil
+}
+
+// keyedMutex provides a lock per key, only keeping the locks in use
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// lock locks the key, returning a func to unlock it
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, exists := k.locks[key]
+	if !exists {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.users++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		l.users--
+		if l.users == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+func (svc *Service) requestToken(calleeID string, form url.Values) (*Token, error) {
+	if svc.oauth == nil {
+		return nil, errors.New("service not created with OAuth")
+	}
+
+	method := "POST"
+
+	path := bytes.NewBuffer([]byte(svc.oauth.IdentityBasePath))
+	path.WriteString("/connect/token")
+
+	req, err := api.BuildRequest(UserAgent, "application/x-www-form-urlencoded", method, path.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(svc.oauth.ClientID, svc.oauth.ClientSecret)
+
+	// the form, the client secret and the tokens returned are credentials so are not logged
+	var result = struct {
+		Token
+		ExpiresIn int64 `json:"expires_in"`
+	}{}
+	res, _, err := svc.doAndDecodeSensitive(calleeID, req, form.Encode(), &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	if result.AccessToken == "" {
+		return nil, errors.New("invalid response, missing access_token")
+	}
+	token := result.Token
+	if result.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return &token, nil
+}
+
+// RegisterFundraisingPageForEventWithToken registers a fundraising page on the JustGiving website on behalf of the user who authorized the Token
+func (svc *Service) RegisterFundraisingPageForEventWithToken(token *Token, page models.FundraisingPageForEvent) (pageURL *url.URL, signOnURL *url.URL, err error) {
+	return svc.registerFundraisingPageForEvent(token, page)
+}
+
+// RegisterFundraisingPageForCampaignWithToken registers a fundraising page for a charity campaign on behalf of the user who authorized the Token
+func (svc *Service) RegisterFundraisingPageForCampaignWithToken(token *Token, page models.FundraisingPageForCampaign) (pageURL *url.URL, signOnURL *url.URL, err error) {
+	return svc.registerFundraisingPageForCampaign(token, page)
+}
+
+// DonationsForUserWithToken returns the donations made by the user who authorized the Token
+func (svc *Service) DonationsForUserWithToken(token *Token, opts DonationsForUserOptions) ([]models.Donation, error) {
+	return svc.donationsForUser(token, opts)
+}
+
+// AddOfflineDonationWithToken records an offline donation against the specified fundraising page on behalf of the user who authorized the Token
+func (svc *Service) AddOfflineDonationWithToken(token *Token, page *FundraisingPageRef, donation models.OfflineDonation) (id uint, err error) {
+	return svc.addOfflineDonation(token, page, donation)
+}
+
+// OfflineDonationsWithToken returns the offline donations recorded against the specified fundraising page on behalf of the user who authorized the Token
+func (svc *Service) OfflineDonationsWithToken(token *Token, page *FundraisingPageRef) ([]models.OfflineDonation, error) {
+	return svc.offlineDonations(token, page)
+}
+
+// DeleteOfflineDonationWithToken removes an offline donation from the specified fundraising page on behalf of the user who authorized the Token
+func (svc *Service) DeleteOfflineDonationWithToken(token *Token, page *FundraisingPageRef, id uint) error {
+	return svc.deleteOfflineDonation(token, page, id)
+}
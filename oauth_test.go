@@ -0,0 +1,251 @@
+package justin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/homemade/justin/api"
+)
+
+// fakeIdentityServer implements enough of the JustGiving identity server token endpoint to test the OAuth2 flow
+func fakeIdentityServer(t *testing.T, clientID string, clientSecret string) *httptest.Server {
+	var mu sync.Mutex
+	issued := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/connect/token" || r.Method != "POST" {
+			http.NotFound(w, r)
+			return
+		}
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != clientID || secret != clientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			if r.PostForm.Get("code") != "thecode" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "therefreshtoken" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		issued++
+		accessToken := fmt.Sprintf("accesstoken%d", issued)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  accessToken,
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": "therefreshtoken",
+		})
+	}))
+}
+
+func createOAuthService(t *testing.T, identityBasePath string) *Service {
+	svc, err := CreateWithOAuth(APIKeyContext{
+		APIKey: "testkey", Env: Sandbox, Timeout: time.Duration(5) * time.Second, SkipValidation: true,
+	}, OAuthContext{
+		ClientID:         "testclient",
+		ClientSecret:     "testsecret",
+		RedirectURL:      "https://example.com/callback",
+		Scopes:           []string{"openid", "account", "fundraise", "offline_access"},
+		IdentityBasePath: identityBasePath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestOAuthAuthorizeURL(t *testing.T) {
+	svc := createOAuthService(t, "")
+	authURL, err := svc.AuthorizeURL("xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme+"://"+u.Host != sandboxIdentityBasePath || u.Path != "/connect/authorize" {
+		t.Errorf("expected AuthorizeURL to use the sandbox identity server but returned %s", authURL)
+	}
+	q := u.Query()
+	if q.Get("client_id") != "testclient" || q.Get("response_type") != "code" || q.Get("state") != "xyz" ||
+		q.Get("redirect_uri") != "https://example.com/callback" || q.Get("scope") != "openid account fundraise offline_access" {
+		t.Errorf("AuthorizeURL query is not as expected, see %s", authURL)
+	}
+}
+
+func TestOAuthTokenFlow(t *testing.T) {
+	identity := fakeIdentityServer(t, "testclient", "testsecret")
+	defer identity.Close()
+	svc := createOAuthService(t, identity.URL)
+
+	// Invalid code
+	if _, err := svc.ExchangeCode("invalidcode"); err == nil {
+		t.Error("expected ExchangeCode to return error for an invalid code")
+	}
+	// Valid code
+	token, err := svc.ExchangeCode("thecode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accesstoken1" || token.RefreshToken != "therefreshtoken" || !token.Valid() {
+		t.Fatalf("token is not as expected, see %#v", token)
+	}
+	if err = svc.SetToken("user1", token); err != nil {
+		t.Fatal(err)
+	}
+	// Valid tokens are returned as is
+	stored, err := svc.TokenFor("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.AccessToken != "accesstoken1" {
+		t.Errorf("expected TokenFor to return the stored token but returned %#v", stored)
+	}
+	// Expired tokens are refreshed and saved
+	token.Expiry = time.Now().Add(-time.Minute)
+	refreshed, err := svc.TokenFor("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.AccessToken != "accesstoken2" || !refreshed.Valid() {
+		t.Errorf("expected TokenFor to refresh the expired token but returned %#v", refreshed)
+	}
+	stored, err = svc.oauth.TokenStore.Token("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.AccessToken != "accesstoken2" {
+		t.Errorf("expected TokenFor to save the refreshed token but stored %#v", stored)
+	}
+	// Unknown keys
+	if _, err = svc.TokenFor("user2"); err == nil {
+		t.Error("expected TokenFor to return error for an unknown key")
+	}
+}
+
+func TestOAuthConcurrentRefresh(t *testing.T) {
+	identity := fakeIdentityServer(t, "testclient", "testsecret")
+	defer identity.Close()
+	svc := createOAuthService(t, identity.URL)
+	expired := &Token{AccessToken: "expired", TokenType: "Bearer", RefreshToken: "therefreshtoken", Expiry: time.Now().Add(-time.Minute)}
+	if err := svc.SetToken("user1", expired); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	tokens := make([]*Token, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := svc.TokenFor("user1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			tokens[i] = token
+		}(i)
+	}
+	wg.Wait()
+
+	// the first token issued is the only refresh, every caller gets it
+	for _, token := range tokens {
+		if token == nil || token.AccessToken != "accesstoken1" {
+			t.Errorf("expected every caller to get the single refreshed token but got %#v", token)
+		}
+	}
+	next, err := svc.ExchangeCode("thecode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.AccessToken != "accesstoken2" {
+		t.Errorf("expected a single refresh before the exchange but the exchange issued %s", next.AccessToken)
+	}
+	if len(svc.refreshes.locks) != 0 {
+		t.Errorf("expected the refresh locks to be released, have %d", len(svc.refreshes.locks))
+	}
+}
+
+func TestOAuthTokenLogging(t *testing.T) {
+	identity := fakeIdentityServer(t, "testclient", "testsecret")
+	defer identity.Close()
+
+	secrets := []string{
+		"thecode", "accesstoken", "therefreshtoken", "testsecret",
+		base64.StdEncoding.EncodeToString([]byte("testclient:testsecret")),
+	}
+	for name, logger := range map[string]func(w io.Writer) api.Logger{"BasicLogger": api.BasicLogger, "JSONLogger": api.JSONLogger} {
+		var buf bytes.Buffer
+		svc := createOAuthService(t, identity.URL)
+		svc.HTTPLogger = logger(&buf)
+
+		token, err := svc.ExchangeCode("thecode")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = svc.RefreshToken(token); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(buf.String(), "\n") != 2 {
+			t.Fatalf("%s expected 2 calls to be logged, see %s", name, buf.String())
+		}
+		for _, secret := range secrets {
+			if strings.Contains(buf.String(), secret) {
+				t.Errorf("%s logged %s, see %s", name, secret, buf.String())
+			}
+		}
+	}
+}
+
+func TestOAuthBearerAuthentication(t *testing.T) {
+	var auth string
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"donations":[{"id":1,"amount":"10.00","currencyCode":"GBP","donationDate":"/Date(1474675200000+0000)/"}],"pagination":{"totalPages":1}}`))
+	}))
+
+	donations, err := svc.DonationsForUserWithToken(&Token{AccessToken: "accesstoken1"}, DonationsForUserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer accesstoken1" {
+		t.Errorf("expected Authorization header to be Bearer accesstoken1 but was %s", auth)
+	}
+	if len(donations) != 1 || donations[0].CurrencyCode != "GBP" {
+		t.Errorf("donations are not as expected, see %#v", donations)
+	}
+	// Missing tokens are not sent
+	_, err = svc.DonationsForUserWithToken(nil, DonationsForUserOptions{})
+	if err == nil {
+		t.Error("expected DonationsForUserWithToken to return error for a missing token")
+	}
+}
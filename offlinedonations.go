@@ -0,0 +1,171 @@
+package justin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+
+	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
+)
+
+// AddOfflineDonation records a donation collected offline (e.g. cash or cheque) against the specified JustGiving fundraising page
+//
+// The donation Amount and CurrencyCode are validated before the request is sent
+func (svc *Service) AddOfflineDonation(account mail.Address, password string, page *FundraisingPageRef, donation models.OfflineDonation) (id uint, err error) {
+	return svc.addOfflineDonation(BasicCredentials(account, password), page, donation)
+}
+
+func (svc *Service) addOfflineDonation(creds Credentials, page *FundraisingPageRef, donation models.OfflineDonation) (id uint, err error) {
+
+	if page.shortName == "" {
+		return 0, ErrUnresolvedFundraisingPageRef
+	}
+
+	// validate request payload before sending
+	if !donation.HasValidAmount() {
+		return 0, errors.New("invalid Amount")
+	}
+	// send the amount as a plain decimal e.g. "1,234.50" is "1234.50"
+	amount, _ := donation.AmountMoney()
+	donation.Amount = amount.String()
+	valid, err := donation.HasValidCurrencyCode(svc)
+	if err != nil {
+		return 0, fmt.Errorf("errors running CurrencyCode validation %v", err)
+	}
+	if !valid {
+		return 0, errors.New("invalid CurrencyCode")
+	}
+
+	method := "POST"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/fundraising/pages/")
+	path.WriteString(url.PathEscape(page.shortName))
+	path.WriteString("/offlinedonations")
+
+	sBody, body, err := api.BuildBody("AddOfflineDonation", donation, ContentType)
+	if err != nil {
+		return 0, err
+	}
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), body)
+	if err != nil {
+		return 0, err
+	}
+
+	// This request requires authentication
+	if err = creds.Authorize(req); err != nil {
+		return 0, err
+	}
+
+	var result = struct {
+		ID uint `json:"id"`
+	}{}
+	res, _, err := svc.doAndDecode("AddOfflineDonation", req, sBody, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	if res.StatusCode != 200 && res.StatusCode != 201 {
+		return 0, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result.ID, nil
+
+}
+
+// OfflineDonations returns the offline donations recorded against the specified JustGiving fundraising page
+func (svc *Service) OfflineDonations(account mail.Address, password string, page *FundraisingPageRef) ([]models.OfflineDonation, error) {
+	return svc.offlineDonations(BasicCredentials(account, password), page)
+}
+
+func (svc *Service) offlineDonations(creds Credentials, page *FundraisingPageRef) ([]models.OfflineDonation, error) {
+
+	if page.shortName == "" {
+		return nil, ErrUnresolvedFundraisingPageRef
+	}
+
+	method := "GET"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/fundraising/pages/")
+	path.WriteString(url.PathEscape(page.shortName))
+	path.WriteString("/offlinedonations")
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// This request requires authentication
+	if err = creds.Authorize(req); err != nil {
+		return nil, err
+	}
+
+	var result []models.OfflineDonation
+	res, _, err := svc.doAndDecode("OfflineDonations", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result, nil
+
+}
+
+// DeleteOfflineDonation removes an offline donation previously recorded against the specified JustGiving fundraising page
+func (svc *Service) DeleteOfflineDonation(account mail.Address, password string, page *FundraisingPageRef, id uint) error {
+	return svc.deleteOfflineDonation(BasicCredentials(account, password), page, id)
+}
+
+func (svc *Service) deleteOfflineDonation(creds Credentials, page *FundraisingPageRef, id uint) error {
+
+	if page.shortName == "" {
+		return ErrUnresolvedFundraisingPageRef
+	}
+
+	method := "DELETE"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/fundraising/pages/")
+	path.WriteString(url.PathEscape(page.shortName))
+	path.WriteString("/offlinedonations/")
+	path.WriteString(strconv.FormatUint(uint64(id), 10))
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	// This request requires authentication
+	if err = creds.Authorize(req); err != nil {
+		return err
+	}
+
+	res, _, err := svc.do("DeleteOfflineDonation", req, "")
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != 200 && res.StatusCode != 204 {
+		return fmt.Errorf("invalid response %s", res.Status)
+	}
+	return nil
+
+}
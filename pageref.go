@@ -0,0 +1,221 @@
+package justin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/homemade/justin/api"
+)
+
+// ErrFundraisingPageNotFound is returned when resolving a reference to a fundraising page which does not exist
+var ErrFundraisingPageNotFound = errors.New("fundraising page not found")
+
+// ErrUnresolvedFundraisingPageRef is returned when a reference without a short name is used, resolve it first with ResolveFundraisingPageRef
+var ErrUnresolvedFundraisingPageRef = errors.New("fundraising page reference has no short name")
+
+// NewFundraisingPageRef returns a reference to the fundraising page with the specified short name, use ResolveFundraisingPageRef to fill in its IDs
+func NewFundraisingPageRef(shortName string) *FundraisingPageRef {
+	return &FundraisingPageRef{shortName: shortName}
+}
+
+// FundraisingPageRefByID returns a reference to the fundraising page with the specified ID, use ResolveFundraisingPageRef to fill in its short name
+// (which is required by most API methods)
+func FundraisingPageRefByID(id uint) *FundraisingPageRef {
+	return &FundraisingPageRef{id: id}
+}
+
+// Resolved reports whether the reference has both the ID and short name of the page
+func (r *FundraisingPageRef) Resolved() bool {
+	return r.id > 0 && r.shortName != ""
+}
+
+// Equal reports whether both references are to the same page, compared by ID when both have one and otherwise by short name
+func (r *FundraisingPageRef) Equal(o *FundraisingPageRef) bool {
+	if r == nil || o == nil {
+		return r == o
+	}
+	if r.id > 0 && o.id > 0 {
+		return r.id == o.id
+	}
+	return r.shortName != "" && strings.EqualFold(r.shortName, o.shortName)
+}
+
+func (r *FundraisingPageRef) String() string {
+	if r == nil {
+		return "<nil>"
+	}
+	b, _ := r.MarshalText()
+	return string(b)
+}
+
+type fundraisingPageRefJSON struct {
+	CharityID uint   `json:"charityId,omitempty"`
+	EventID   uint   `json:"eventId,omitempty"`
+	ID        uint   `json:"pageId,omitempty"`
+	ShortName string `json:"pageShortName,omitempty"`
+}
+
+// MarshalJSON encodes all the known fields of the reference e.g. {"charityId":1,"eventId":2,"pageId":3,"pageShortName":"page"}
+func (r FundraisingPageRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fundraisingPageRefJSON{CharityID: r.charityID, EventID: r.eventID, ID: r.id, ShortName: r.shortName})
+}
+
+// UnmarshalJSON decodes a reference encoded by MarshalJSON
+func (r *FundraisingPageRef) UnmarshalJSON(b []byte) error {
+	var v fundraisingPageRefJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = FundraisingPageRef{charityID: v.CharityID, eventID: v.EventID, id: v.ID, shortName: v.ShortName}
+	return nil
+}
+
+// MarshalText encodes the reference as its short name or, if the short name is not known, as "id:" followed by its ID
+//
+// Only the short name (or ID) is kept, use MarshalJSON to keep all the known fields.
+func (r FundraisingPageRef) MarshalText() ([]byte, error) {
+	if r.shortName != "" {
+		return []byte(r.shortName), nil
+	}
+	if r.id > 0 {
+		return []byte("id:" + strconv.FormatUint(uint64(r.id), 10)), nil
+	}
+	return nil, errors.New("empty fundraising page reference")
+}
+
+// UnmarshalText decodes a reference encoded by MarshalText
+func (r *FundraisingPageRef) UnmarshalText(b []byte) error {
+	s := string(b)
+	if strings.HasPrefix(s, "id:") {
+		id, err := strconv.ParseUint(s[3:], 10, 0)
+		if err != nil || id == 0 {
+			return fmt.Errorf("invalid fundraising page reference %q", s)
+		}
+		*r = FundraisingPageRef{id: uint(id)}
+		return nil
+	}
+	if s == "" {
+		return errors.New("empty fundraising page reference")
+	}
+	*r = FundraisingPageRef{shortName: s}
+	return nil
+}
+
+// complete reports whether the reference has all of its fields, so resolving it would not change it
+func (r *FundraisingPageRef) complete() bool {
+	return r.Resolved() && r.charityID > 0 && r.eventID > 0
+}
+
+// ResolveFundraisingPageRef fills in any missing fields of the reference from the JustGiving page details, returning ErrFundraisingPageNotFound if the page does not exist
+//
+// References with all their fields are left unchanged without calling the API, a reference missing its charity or event ID (e.g. reloaded
+// from storage with only the page ID and short name) is resolved even though Resolved reports true.
+func (svc *Service) ResolveFundraisingPageRef(ref *FundraisingPageRef) error {
+	if ref == nil {
+		return errors.New("empty fundraising page reference")
+	}
+	if ref.complete() {
+		return nil
+	}
+	details, err := svc.fundraisingPageDetails(ref)
+	if err != nil {
+		return err
+	}
+	if ref.id == 0 {
+		ref.id = details.PageID
+	}
+	if ref.shortName == "" {
+		ref.shortName = details.PageShortName
+	}
+	if ref.charityID == 0 {
+		ref.charityID = details.Charity.ID
+	}
+	if ref.eventID == 0 {
+		ref.eventID = details.EventID
+	}
+	return nil
+}
+
+// ResolveFundraisingPageRefs resolves the references as ResolveFundraisingPageRef, with at most concurrency (or 4 if not provided) resolved at once
+//
+// Every reference is resolved even if others fail, errs holds the error resolving each reference by index (e.g. ErrFundraisingPageNotFound)
+// and is nil if they were all resolved.
+func (svc *Service) ResolveFundraisingPageRefs(refs []*FundraisingPageRef, concurrency int) (errs []error) {
+	svc, finish := svc.span("ResolveFundraisingPageRefs")
+	var firstErr error
+	defer func() { finish(firstErr) }()
+
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	results := make([]error, len(refs))
+	concurrently(len(refs), concurrency, func(i int) {
+		if err := svc.ResolveFundraisingPageRef(refs[i]); err != nil {
+			results[i] = fmt.Errorf("error resolving fundraising page %s, %w", refs[i], err)
+		}
+	})
+	for _, err := range results {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return nil
+	}
+	return results
+}
+
+type fundraisingPageDetails struct {
+	PageID        uint   `json:"pageId"`
+	PageShortName string `json:"pageShortName"`
+	EventID       uint   `json:"eventId"`
+	Domain        string `json:"domain"`
+	Charity       struct {
+		ID uint `json:"id"`
+	} `json:"charity"`
+}
+
+// fundraisingPageDetails returns the details of the referenced page, by short name if known and otherwise by ID
+func (svc *Service) fundraisingPageDetails(ref *FundraisingPageRef) (*fundraisingPageDetails, error) {
+
+	method := "GET"
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	switch {
+	case ref.shortName != "":
+		path.WriteString("/v1/fundraising/pages/")
+		path.WriteString(url.PathEscape(ref.shortName))
+	case ref.id > 0:
+		path.WriteString("/v1/fundraising/pagebyid/")
+		path.WriteString(strconv.FormatUint(uint64(ref.id), 10))
+	default:
+		return nil, errors.New("empty fundraising page reference")
+	}
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result fundraisingPageDetails
+	res, _, err := svc.doAndDecode("FundraisingPageDetails", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == 404 {
+		return nil, ErrFundraisingPageNotFound
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return &result, nil
+}
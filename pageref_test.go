@@ -0,0 +1,145 @@
+package justin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFundraisingPageRefMarshalling(t *testing.T) {
+	ref := &FundraisingPageRef{charityID: 1, eventID: 2, id: 3, shortName: "page"}
+	b, err := json.Marshal(ref)
+	if err != nil || string(b) != `{"charityId":1,"eventId":2,"pageId":3,"pageShortName":"page"}` {
+		t.Fatalf("unexpected JSON %s %v", b, err)
+	}
+	var decoded FundraisingPageRef
+	if err = json.Unmarshal(b, &decoded); err != nil || decoded != *ref {
+		t.Errorf("expected %+v, got %+v %v", *ref, decoded, err)
+	}
+
+	for _, tc := range []struct {
+		ref  *FundraisingPageRef
+		text string
+	}{
+		{NewFundraisingPageRef("page"), "page"},
+		{FundraisingPageRefByID(3), "id:3"},
+	} {
+		b, err = tc.ref.MarshalText()
+		if err != nil || string(b) != tc.text {
+			t.Errorf("expected %s, got %s %v", tc.text, b, err)
+		}
+		var decoded FundraisingPageRef
+		if err = decoded.UnmarshalText(b); err != nil || decoded != *tc.ref {
+			t.Errorf("expected %+v, got %+v %v", *tc.ref, decoded, err)
+		}
+	}
+	for _, text := range []string{"", "id:", "id:0", "id:x"} {
+		var decoded FundraisingPageRef
+		if err = decoded.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("expected %q to be invalid", text)
+		}
+	}
+}
+
+func TestFundraisingPageRefEqual(t *testing.T) {
+	for _, tc := range []struct {
+		a, b  *FundraisingPageRef
+		equal bool
+	}{
+		{NewFundraisingPageRef("page"), NewFundraisingPageRef("Page"), true},
+		{FundraisingPageRefByID(3), &FundraisingPageRef{id: 3, shortName: "page"}, true},
+		{&FundraisingPageRef{id: 3, shortName: "page"}, &FundraisingPageRef{id: 4, shortName: "page"}, false},
+		{FundraisingPageRefByID(3), NewFundraisingPageRef("page"), false},
+		{NewFundraisingPageRef(""), NewFundraisingPageRef(""), false},
+		{nil, NewFundraisingPageRef("page"), false},
+		{nil, nil, true},
+	} {
+		if tc.a.Equal(tc.b) != tc.equal {
+			t.Errorf("expected %s equal to %s to be %t", tc.a, tc.b, tc.equal)
+		}
+	}
+}
+
+func TestResolveFundraisingPageRef(t *testing.T) {
+	var calls int32
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/fundraising/pagebyid/3"), strings.HasSuffix(r.URL.Path, "/v1/fundraising/pages/page"):
+			w.Write([]byte(`{"pageId": 3, "pageShortName": "page", "eventId": 2, "charity": {"id": 1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	expected := FundraisingPageRef{charityID: 1, eventID: 2, id: 3, shortName: "page"}
+	refs := []*FundraisingPageRef{FundraisingPageRefByID(3), NewFundraisingPageRef("page"), &expected}
+	if errs := svc.ResolveFundraisingPageRefs(refs, 0); errs != nil {
+		t.Fatal(errs)
+	}
+	for _, ref := range refs {
+		if *ref != expected {
+			t.Errorf("expected %+v, got %+v", expected, *ref)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected resolved references not to call the API, got %d calls", calls)
+	}
+
+	// a reference with the page ID and short name but no charity or event ID is completed
+	partial := &FundraisingPageRef{id: 3, shortName: "page"}
+	if !partial.Resolved() {
+		t.Fatal("expected a reference with an ID and short name to be resolved")
+	}
+	if err := svc.ResolveFundraisingPageRef(partial); err != nil {
+		t.Fatal(err)
+	}
+	if *partial != expected || calls != 3 {
+		t.Errorf("expected %+v after another call, got %+v after %d calls", expected, *partial, calls)
+	}
+
+	// a missing page doesn't stop the rest of the batch being resolved
+	refs = []*FundraisingPageRef{NewFundraisingPageRef("missing"), FundraisingPageRefByID(3), NewFundraisingPageRef("page")}
+	errs := svc.ResolveFundraisingPageRefs(refs, 2)
+	if len(errs) != 3 || !errors.Is(errs[0], ErrFundraisingPageNotFound) || errs[1] != nil || errs[2] != nil {
+		t.Fatalf("expected only the missing page to fail, got %v", errs)
+	}
+	for _, ref := range refs[1:] {
+		if *ref != expected {
+			t.Errorf("expected %+v, got %+v", expected, *ref)
+		}
+	}
+
+	if err := svc.ResolveFundraisingPageRef(NewFundraisingPageRef("missing")); !errors.Is(err, ErrFundraisingPageNotFound) {
+		t.Errorf("expected ErrFundraisingPageNotFound, got %v", err)
+	}
+	if _, err := svc.FundraisingPageResults(FundraisingPageRefByID(4)); !errors.Is(err, ErrUnresolvedFundraisingPageRef) {
+		t.Errorf("expected ErrUnresolvedFundraisingPageRef, got %v", err)
+	}
+}
+
+func TestFundraisingPageRefPathEscaping(t *testing.T) {
+	var paths []string
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	var ref FundraisingPageRef
+	if err := ref.UnmarshalText([]byte("a/../x?b")); err != nil {
+		t.Fatal(err)
+	}
+	svc.ResolveFundraisingPageRef(&ref)
+	svc.FundraisingPageResults(&ref)
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/testkey/v1/fundraising/pages/a%2F..%2Fx%3Fb") || !strings.HasSuffix(p, "?") {
+			t.Errorf("expected the short name to be escaped, got %s", p)
+		}
+	}
+	if len(paths) != 2 {
+		t.Errorf("expected 2 calls, got %v", paths)
+	}
+}
@@ -0,0 +1,171 @@
+package justin
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
+)
+
+// DefaultReferenceDataTTL is the duration the JustGiving countries and currencies lists are cached for when APIKeyContext.ReferenceDataTTL is not set
+const DefaultReferenceDataTTL = 24 * time.Hour
+
+// referenceDataCache holds the JustGiving reference data lists for a Service, the zero value is ready to use
+type referenceDataCache struct {
+	mu sync.Mutex
+
+	countries  cachedList
+	currencies cachedList
+}
+
+// cachedList is a cached reference data list, when it expires one caller fetches it again while any others wait for the result
+type cachedList struct {
+	value  interface{}
+	expiry time.Time
+	fetch  *listFetch
+}
+
+// listFetch is a fetch of a cachedList in progress, done is closed once the value and err are set
+type listFetch struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// get returns the list if it has not expired, otherwise it fetches the list (outside the lock) and caches it for the ttl
+func (c *referenceDataCache) get(list *cachedList, ttl time.Duration, fetch func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if list.value != nil && time.Now().Before(list.expiry) {
+		value := list.value
+		c.mu.Unlock()
+		return value, nil
+	}
+	if f := list.fetch; f != nil {
+		// another caller is already fetching the list
+		c.mu.Unlock()
+		<-f.done
+		return f.value, f.err
+	}
+	f := &listFetch{done: make(chan struct{})}
+	list.fetch = f
+	c.mu.Unlock()
+
+	f.value, f.err = fetch()
+
+	c.mu.Lock()
+	if f.err == nil {
+		list.value, list.expiry = f.value, time.Now().Add(ttl)
+	}
+	list.fetch = nil
+	c.mu.Unlock()
+	close(f.done)
+	return f.value, f.err
+}
+
+func (svc *Service) referenceDataTTL() time.Duration {
+	if svc.ReferenceDataTTL > 0 {
+		return svc.ReferenceDataTTL
+	}
+	return DefaultReferenceDataTTL
+}
+
+// Countries returns the published JustGiving countries list
+//
+// The list is cached for the APIKeyContext.ReferenceDataTTL, a copy is returned so it can be changed by the caller
+func (svc *Service) Countries() ([]models.Country, error) {
+	countries, err := svc.countries()
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Country(nil), countries...), nil
+}
+
+// countries returns the cached countries list, which must not be changed
+func (svc *Service) countries() ([]models.Country, error) {
+	value, err := svc.referenceData.get(&svc.referenceData.countries, svc.referenceDataTTL(), func() (interface{}, error) {
+		return svc.fetchCountries()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]models.Country), nil
+}
+
+func (svc *Service) fetchCountries() ([]models.Country, error) {
+
+	method := "GET"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/countries")
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.Country
+	res, _, err := svc.doAndDecode("Countries", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result, nil
+}
+
+// Currencies returns the published JustGiving currency code list
+//
+// The list is cached for the APIKeyContext.ReferenceDataTTL, a copy is returned so it can be changed by the caller
+func (svc *Service) Currencies() ([]models.Currency, error) {
+	currencies, err := svc.currencies()
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Currency(nil), currencies...), nil
+}
+
+// currencies returns the cached currency code list, which must not be changed
+func (svc *Service) currencies() ([]models.Currency, error) {
+	value, err := svc.referenceData.get(&svc.referenceData.currencies, svc.referenceDataTTL(), func() (interface{}, error) {
+		return svc.fetchCurrencies()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]models.Currency), nil
+}
+
+func (svc *Service) fetchCurrencies() ([]models.Currency, error) {
+
+	method := "GET"
+
+	path := bytes.NewBuffer([]byte(svc.BasePath))
+	path.WriteString("/")
+	path.WriteString(svc.APIKey)
+	path.WriteString("/v1/fundraising/currencies")
+
+	req, err := api.BuildRequest(UserAgent, ContentType, method, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.Currency
+	res, _, err := svc.doAndDecode("Currencies", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("invalid response %s", res.Status)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package justin
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/homemade/justin/models"
+)
+
+// fakeReferenceData serves the countries and currencies lists, counting the requests for each
+func fakeReferenceData(t *testing.T, countries *int32, currencies *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/testkey/v1/countries":
+			atomic.AddInt32(countries, 1)
+			// slow enough for concurrent callers to overlap
+			time.Sleep(10 * time.Millisecond)
+			json.NewEncoder(w).Encode([]models.Country{{Name: "United Kingdom", Code: "GB"}, {Name: "Ireland", Code: "IE"}})
+		case "/testkey/v1/fundraising/currencies":
+			atomic.AddInt32(currencies, 1)
+			json.NewEncoder(w).Encode([]models.Currency{{Code: "GBP", Symbol: "£", Description: "British Pounds"}})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestReferenceDataCache(t *testing.T) {
+	var countryRequests, currencyRequests int32
+	ttl := 100 * time.Millisecond
+	svc := newTestService(t, fakeReferenceData(t, &countryRequests, &currencyRequests), func(ctx *APIKeyContext) {
+		ctx.ReferenceDataTTL = ttl
+	})
+
+	// concurrent callers share a single request
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.Countries(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&countryRequests); n != 1 {
+		t.Errorf("expected 1 countries request, was %d", n)
+	}
+
+	// names and codes are matched case-insensitively from the cache
+	for _, name := range []string{"United Kingdom", "united kingdom", "IRELAND"} {
+		valid, err := svc.IsValidCountry(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !valid {
+			t.Errorf("expected %q to be a valid country", name)
+		}
+	}
+	if valid, err := svc.IsValidCountry("Atlantis"); err != nil || valid {
+		t.Errorf("expected Atlantis to be an invalid country, was %t %v", valid, err)
+	}
+	for _, code := range []string{"GBP", "gbp"} {
+		valid, err := svc.IsValidCurrencyCode(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !valid {
+			t.Errorf("expected %q to be a valid currency code", code)
+		}
+	}
+	if valid, err := svc.IsValidCurrencyCode("XXX"); err != nil || valid {
+		t.Errorf("expected XXX to be an invalid currency code, was %t %v", valid, err)
+	}
+	if countryRequests != 1 || currencyRequests != 1 {
+		t.Errorf("expected 1 request for each list, was %d countries %d currencies", countryRequests, currencyRequests)
+	}
+
+	// changing the returned list does not change the cache
+	countries, err := svc.Countries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	countries[0].Name = "Changed"
+	if countries, err = svc.Countries(); err != nil {
+		t.Fatal(err)
+	}
+	if countries[0].Name != "United Kingdom" {
+		t.Errorf("expected the cached countries to be unchanged, was %q", countries[0].Name)
+	}
+
+	// the lists are fetched again once the ttl expires
+	time.Sleep(ttl + 10*time.Millisecond)
+	if _, err = svc.Countries(); err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := svc.IsValidCurrencyCode("gbp"); err != nil || !valid {
+		t.Errorf("expected gbp to be a valid currency code, was %t %v", valid, err)
+	}
+	if countryRequests != 2 || currencyRequests != 2 {
+		t.Errorf("expected 2 requests for each list after the ttl, was %d countries %d currencies", countryRequests, currencyRequests)
+	}
+}
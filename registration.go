@@ -0,0 +1,166 @@
+package justin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/homemade/justin/models"
+)
+
+// PageRegistration is the result of registering a fundraising page with EnsureFundraisingPageForEvent
+//
+// Existed is true when the page had already been registered, e.g. by an earlier request which timed out. SignOnURL is only set when the page
+// is registered by this call: JustGiving issues a sign on URL solely in the response to registering a page, and the API has no method to
+// request one for an existing page (the page and account endpoints used to find it don't return one), so it is nil when Existed is true.
+type PageRegistration struct {
+	Page      *FundraisingPageRef
+	PageURL   *url.URL
+	SignOnURL *url.URL
+	Existed   bool
+}
+
+// EnsureFundraisingPageForEvent registers a fundraising page as RegisterFundraisingPageForEvent, but can safely be retried
+//
+// When registration times out or the short name is already taken, the pages of the user account are checked for a page with the same short name
+// and event, which is returned (with Existed set) instead of an error. If registration timed out before the page was created it is retried once.
+// Checking for the page requires further API calls, so a service with a context deadline should allow time for them. Once the context of the
+// service is done (e.g. its deadline has passed) the registration error is returned as is.
+func (svc *Service) EnsureFundraisingPageForEvent(account mail.Address, password string, page models.FundraisingPageForEvent) (*PageRegistration, error) {
+	return svc.ensureFundraisingPageForEvent(account, BasicCredentials(account, password), page)
+}
+
+func (svc *Service) ensureFundraisingPageForEvent(owner mail.Address, creds Credentials, page models.FundraisingPageForEvent) (result *PageRegistration, err error) {
+
+	svc, finish := svc.span("EnsureFundraisingPageForEvent")
+	defer func() { finish(err) }()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		var pageURL, signOnURL *url.URL
+		pageURL, signOnURL, err = svc.withRetries(attempt).registerFundraisingPageForEvent(creds, page)
+		if err == nil {
+			return &PageRegistration{
+				Page:      &FundraisingPageRef{charityID: page.CharityID, eventID: page.EventID, shortName: page.PageShortName},
+				PageURL:   pageURL,
+				SignOnURL: signOnURL,
+			}, nil
+		}
+		if svc.context().Err() != nil {
+			// the lookup and any retry would fail too, e.g. the deadline of the service context has passed
+			return nil, err
+		}
+		conflict, timeout := registrationConflict(err), registrationTimeout(err)
+		if !conflict && !timeout {
+			return nil, err
+		}
+		existing, lookupErr := svc.registeredFundraisingPage(owner, page)
+		if lookupErr != nil {
+			return nil, &ExistingPageError{Err: err, LookupErr: lookupErr}
+		}
+		if existing != nil {
+			return existing, nil
+		}
+		if conflict {
+			// the short name is taken by another page
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// ExistingPageError is returned by EnsureFundraisingPageForEvent when registration failed and checking for an existing page also failed
+//
+// Err is the registration error, use errors.As to retrieve it as a *RegistrationError. LookupErr is the error checking for an existing page.
+type ExistingPageError struct {
+	Err       error
+	LookupErr error
+}
+
+func (e *ExistingPageError) Error() string {
+	return fmt.Sprintf("%v, error checking for an existing page %v", e.Err, e.LookupErr)
+}
+
+// Unwrap returns both the registration and lookup errors
+func (e *ExistingPageError) Unwrap() []error {
+	return []error{e.Err, e.LookupErr}
+}
+
+// shortNameTakenIDs are the JustGiving error ids reported when a page short name is already taken
+var shortNameTakenIDs = []string{"PageShortNameAlreadyExists"}
+
+// registrationConflict reports whether registration failed as the page short name is already taken
+func registrationConflict(err error) bool {
+	var regErr *RegistrationError
+	if !errors.As(err, &regErr) {
+		return false
+	}
+	if regErr.StatusCode == 409 {
+		return true
+	}
+	for _, e := range regErr.Errors {
+		for _, id := range shortNameTakenIDs {
+			if strings.EqualFold(e.Code, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registrationTimeout reports whether registration failed without knowing if JustGiving registered the page
+//
+// Only transport and client timeouts (and the 408 and 504 statuses) are counted, a deadline of the service context is not as it has passed.
+func registrationTimeout(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var regErr *RegistrationError
+	return errors.As(err, &regErr) && (regErr.StatusCode == 408 || regErr.StatusCode == 504)
+}
+
+// registeredFundraisingPage returns the page of the owner with the same short name and event as the page, or nil if there isn't one
+func (svc *Service) registeredFundraisingPage(owner mail.Address, page models.FundraisingPageForEvent) (*PageRegistration, error) {
+	refs, err := svc.FundraisingPagesForCharityAndUser(page.CharityID, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if !strings.EqualFold(ref.shortName, page.PageShortName) || ref.eventID != page.EventID {
+			continue
+		}
+		details, err := svc.fundraisingPageDetails(ref)
+		if errors.Is(err, ErrFundraisingPageNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if details.EventID != 0 && details.EventID != page.EventID {
+			return nil, nil
+		}
+		if ref.id == 0 {
+			ref.id = details.PageID
+		}
+		return &PageRegistration{
+			Page:    ref,
+			PageURL: svc.fundraisingPageURL(details),
+			Existed: true,
+		}, nil
+	}
+	return nil, nil
+}
+
+// fundraisingPageURL returns the website URL of a page, on the domain in its details or the website of the API environment
+func (svc *Service) fundraisingPageURL(details *fundraisingPageDetails) *url.URL {
+	domain := details.Domain
+	if domain == "" {
+		if base, err := url.Parse(svc.BasePath); err == nil {
+			domain = strings.Replace(base.Host, "api.", "www.", 1)
+		}
+	}
+	return &url.URL{Scheme: "https", Host: domain, Path: "/fundraising/" + details.PageShortName}
+}
@@ -0,0 +1,330 @@
+package justin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/homemade/justin/api"
+	"github.com/homemade/justin/models"
+)
+
+func TestRegistrationError(t *testing.T) {
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/v1/fundraising/currencies") {
+			w.Write([]byte(`[{"currencyCode": "GBP"}]`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`[{"id": "PageShortNameAlreadyExists", "desc": "The page short name is already taken"}, {"id": "TargetAmountInvalid", "desc": "The target amount is invalid"}, {"id": "Unknown", "desc": "Something went wrong"}]`))
+	}))
+
+	page := models.FundraisingPageForEvent{CharityID: 1, EventID: 2, PageShortName: "taken", PageTitle: "Taken", TargetAmount: "100", CurrencyCode: "GBP"}
+	_, _, err := svc.RegisterFundraisingPageForEventWithCredentials(NoCredentials(), page)
+	var regErr *RegistrationError
+	if !errors.As(err, &regErr) || regErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected RegistrationError, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid response 400 Bad Request, result of running validation on request payload was: no errors found, JustGiving reported: ") {
+		t.Errorf("unexpected error message %v", err)
+	}
+	var ve models.ValidationErrors
+	if !errors.As(err, &ve) || len(ve) != 3 {
+		t.Fatalf("expected 3 ValidationErrors, got %v", err)
+	}
+	for i, field := range []string{"PageShortName", "TargetAmount", ""} {
+		if ve[i].Field != field {
+			t.Errorf("expected error %d to be for %q, got %q", i, field, ve[i].Field)
+		}
+	}
+	if ve[0].Code != "PageShortNameAlreadyExists" || ve[0].Message != "The page short name is already taken" {
+		t.Errorf("unexpected error %+v", ve[0])
+	}
+}
+
+func TestPasswordBodiesNotLogged(t *testing.T) {
+	justgiving := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/v1/account/validate") {
+			w.Write([]byte(`{"isValid": true}`))
+		}
+	})
+
+	for _, logPasswords := range []bool{false, true} {
+		var buf bytes.Buffer
+		svc := newTestService(t, justgiving, func(ctx *APIKeyContext) {
+			ctx.HTTPLogger = api.JSONLogger(&buf)
+			ctx.LogPasswordBodies = logPasswords
+		})
+
+		if _, err := svc.Validate(mail.Address{Address: "test@example.com"}, "secretpassword"); err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.AccountRegistration(models.Account{Email: mail.Address{Address: "test@example.com"}, Password: "secretpassword"}); err != nil {
+			t.Fatal(err)
+		}
+		if logged := strings.Count(buf.String(), "secretpassword"); (logPasswords && logged != 2) || (!logPasswords && logged != 0) {
+			t.Errorf("with LogPasswordBodies %t the password was logged %d times, see %s", logPasswords, logged, buf.String())
+		}
+	}
+}
+
+func TestRegisterFundraisingPageForEventBody(t *testing.T) {
+	var body map[string]interface{}
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"signOnUrl": "https://www.justgiving.com/signon", "next": {"uri": "https://www.justgiving.com/page"}}`))
+	}))
+
+	img1, _ := url.Parse("https://example.com/1.jpg")
+	img2, _ := url.Parse("https://example.com/2.jpg")
+	page := models.FundraisingPageForEvent{CharityID: 1, EventID: 2, PageShortName: "page", PageTitle: "Page", CurrencyCode: "GBP"}
+	if _, _, err := svc.RegisterFundraisingPageForEventWithCredentials(NoCredentials(), page); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"customCodes", "images", "activityType", "eventDate", "expiryDate", "reference", "rememberedPersonReference", "tags"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("expected %s not to be sent, got %v", key, body[key])
+		}
+	}
+
+	page.CustomCodes = models.CustomCodes{2: "CODE2", 5: ""}
+	page.Images = []models.Image{{URL: *img1}, {URL: *img2, IsDefault: true, Caption: `Finish "line"`}}
+	page.PageTitle = `Ada's "Page"`
+	page.PageStory = "Line 1\nLine 2 \\"
+	page.ActivityType = "InMemory"
+	page.EventDate = models.JGDate{Time: time.Date(2016, 9, 24, 0, 0, 0, 0, time.UTC)}
+	page.Reference = `ref "1"`
+	page.RememberedPerson = &models.RememberedPerson{ID: 7}
+	page.RememberedPersonRelationship = "Mother"
+	page.Tags = []models.Tag{{ID: "team", Value: "blue"}}
+	if _, _, err := svc.RegisterFundraisingPageForEventWithCredentials(NoCredentials(), page); err != nil {
+		t.Fatal(err)
+	}
+	codes, _ := json.Marshal(body["customCodes"])
+	if string(codes) != `{"customCode2":"CODE2","customCode5":""}` {
+		t.Errorf("unexpected customCodes %s", codes)
+	}
+	images, _ := json.Marshal(body["images"])
+	if string(images) != `[{"caption":"","isDefault":false,"url":"https://example.com/1.jpg"},{"caption":"Finish \"line\"","isDefault":true,"url":"https://example.com/2.jpg"}]` {
+		t.Errorf("expected the second image to be the default, got %s", images)
+	}
+	if body["pageTitle"] != page.PageTitle || body["pageStory"] != page.PageStory {
+		t.Errorf("expected the title and story unchanged, got %v and %v", body["pageTitle"], body["pageStory"])
+	}
+	if body["activityType"] != "InMemory" || body["eventDate"] != "/Date(1474675200000+0000)/" || body["reference"] != `ref "1"` {
+		t.Errorf("unexpected optional fields %v", body)
+	}
+	if _, ok := body["expiryDate"]; ok {
+		t.Error("expected expiryDate not to be sent")
+	}
+	tags, _ := json.Marshal(body["tags"])
+	person, _ := json.Marshal(body["rememberedPersonReference"])
+	if string(tags) != `[{"id":"team","value":"blue"}]` || string(person) != `{"relationship":"Mother","rememberedPerson":{"id":7}}` {
+		t.Errorf("unexpected tags %s or rememberedPersonReference %s", tags, person)
+	}
+
+	page.CustomCodes = models.CustomCodes{7: "CODE7"}
+	if _, _, err := svc.RegisterFundraisingPageForEventWithCredentials(NoCredentials(), page); err == nil {
+		t.Error("expected custom code 7 to be rejected")
+	}
+	page.CustomCodes = nil
+
+	// a page remembering a person is an in memory page, unless another ActivityType is set
+	page.ActivityType = ""
+	page.RememberedPerson = &models.RememberedPerson{FirstName: "Ada", LastName: "Lovelace", DateOfDeath: models.JGDate{Time: time.Date(1852, 11, 27, 0, 0, 0, 0, time.UTC)}}
+	page.RememberedPersonRelationship = ""
+	if _, _, err := svc.RegisterFundraisingPageForEventWithCredentials(NoCredentials(), page); err != nil {
+		t.Fatal(err)
+	}
+	person, _ = json.Marshal(body["rememberedPersonReference"])
+	if body["activityType"] != "InMemory" || string(person) != `{"rememberedPerson":{"dateOfDeath":"/Date(-3695155200000+0000)/","firstName":"Ada","lastName":"Lovelace"}}` {
+		t.Errorf("expected an in memory page, got activityType %v and rememberedPersonReference %s", body["activityType"], person)
+	}
+}
+
+func TestEnsureFundraisingPageForEvent(t *testing.T) {
+	var mu sync.Mutex
+	var registered []string
+	created := map[string]bool{"taken-by-someone-else": true}
+	slow := map[string]bool{}
+	failLookup := false
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "PUT":
+			var body struct {
+				PageShortName string `json:"pageShortName"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			name := body.PageShortName
+			registered = append(registered, name)
+			if created[name] {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`[{"id": "PageShortNameAlreadyExists", "desc": "The page short name is already taken"}]`))
+				return
+			}
+			if slow[name] {
+				// time out, creating the page unless it is slow to create
+				delete(slow, name)
+				if !strings.HasPrefix(name, "not-created") {
+					created[name] = true
+				}
+				mu.Unlock()
+				time.Sleep(200 * time.Millisecond)
+				mu.Lock()
+				return
+			}
+			created[name] = true
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"signOnUrl": "https://www.justgiving.com/signon", "next": {"uri": "https://www.justgiving.com/fundraising/` + name + `"}}`))
+		case strings.HasSuffix(r.URL.Path, "/v1/fundraising/currencies"):
+			w.Write([]byte(`[{"currencyCode": "GBP"}]`))
+		case strings.Contains(r.URL.Path, "/v1/account/rob@golang.org/pages"):
+			if failLookup {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var pages []string
+			for name := range created {
+				if name != "taken-by-someone-else" {
+					pages = append(pages, `{"eventId": 2, "pageId": 3, "pageShortName": "`+name+`"}`)
+				}
+			}
+			w.Write([]byte("[" + strings.Join(pages, ",") + "]"))
+		case strings.Contains(r.URL.Path, "/v1/fundraising/pages/"):
+			name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			w.Write([]byte(`{"pageId": 3, "pageShortName": "` + name + `", "eventId": 2, "domain": "www.justgiving.com", "charity": {"id": 1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), func(ctx *APIKeyContext) {
+		ctx.Timeout = 50 * time.Millisecond
+	})
+	eml := testEmail(t)
+	page := models.FundraisingPageForEvent{CharityID: 1, EventID: 2, PageTitle: "Page", CurrencyCode: "GBP"}
+
+	// a new page
+	page.PageShortName = "new-page"
+	reg, err := svc.EnsureFundraisingPageForEvent(eml, "goph3r", page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.Existed || reg.SignOnURL == nil || reg.PageURL.String() != "https://www.justgiving.com/fundraising/new-page" {
+		t.Errorf("unexpected registration %+v", reg)
+	}
+
+	// registration times out after the page is created, and the retry conflicts
+	for _, name := range []string{"timed-out", "new-page"} {
+		page.PageShortName = name
+		mu.Lock()
+		slow[name] = name == "timed-out"
+		mu.Unlock()
+		reg, err = svc.EnsureFundraisingPageForEventWithCredentials(eml, NoCredentials(), page)
+		if err != nil {
+			t.Fatalf("%s, %v", name, err)
+		}
+		if !reg.Existed || reg.SignOnURL != nil || reg.Page.ID() != 3 || reg.PageURL.String() != "https://www.justgiving.com/fundraising/"+name {
+			t.Errorf("%s, expected the existing page, got %+v", name, reg)
+		}
+	}
+
+	// registration times out before the page is created, so is retried
+	page.PageShortName = "not-created"
+	mu.Lock()
+	slow["not-created"] = true
+	registered = nil
+	mu.Unlock()
+	var retries []int
+	var metrics api.MetricsFunc
+	metrics = func(m api.Metric) {
+		if m.CalleeID == "RegisterFundraisingPageForEvent" {
+			retries = append(retries, m.Retries)
+		}
+	}
+	svc.Metrics = metrics
+	reg, err = svc.EnsureFundraisingPageForEvent(eml, "goph3r", page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.Metrics = nil
+	if reg.Existed || reg.SignOnURL == nil || len(registered) != 2 {
+		t.Errorf("expected the page to be registered on retry, got %+v after %v", reg, registered)
+	}
+	if len(retries) != 2 || retries[0] != 0 || retries[1] != 1 {
+		t.Errorf("expected the second attempt to be observed with 1 retry, got %v", retries)
+	}
+
+	// the deadline of the service context passes, so there is no lookup or retry
+	page.PageShortName = "deadline"
+	mu.Lock()
+	slow["deadline"] = true
+	registered = nil
+	mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = svc.WithContext(ctx).EnsureFundraisingPageForEvent(eml, "goph3r", page)
+	var existingErr *ExistingPageError
+	mu.Lock()
+	attempts := len(registered)
+	mu.Unlock()
+	if !errors.Is(err, context.DeadlineExceeded) || errors.As(err, &existingErr) || attempts != 1 {
+		t.Errorf("expected the deadline error without a retry, got %v after %d attempts", err, attempts)
+	}
+
+	// the short name belongs to another user
+	page.PageShortName = "taken-by-someone-else"
+	if _, err = svc.EnsureFundraisingPageForEvent(eml, "goph3r", page); !registrationConflict(err) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+
+	// checking for an existing page fails, the registration error is kept
+	mu.Lock()
+	failLookup = true
+	mu.Unlock()
+	_, err = svc.EnsureFundraisingPageForEvent(eml, "goph3r", page)
+	var regErr *RegistrationError
+	if !errors.As(err, &existingErr) || !errors.As(err, &regErr) || len(regErr.Errors.Field("PageShortName")) != 1 {
+		t.Errorf("expected the RegistrationError to be kept, got %v", err)
+	}
+}
+
+func TestRegisterFundraisingPageForCampaignBody(t *testing.T) {
+	var body map[string]interface{}
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"signOnUrl": "https://www.justgiving.com/signon", "next": {"uri": "https://www.justgiving.com/page"}}`))
+	}))
+
+	img, _ := url.Parse("https://example.com/1.jpg")
+	page := models.FundraisingPageForCampaign{
+		CampaignID: "guid", CharityID: 1, PageShortName: "page", PageTitle: `Ada's "Page"`, PageStory: `\ "story"`, TargetAmount: "100", CurrencyCode: "GBP",
+		Images: []models.Image{{URL: *img, Caption: `"caption"`}},
+	}
+	if _, _, err := svc.RegisterFundraisingPageForCampaignWithCredentials(NoCredentials(), page); err != nil {
+		t.Fatal(err)
+	}
+	images, _ := json.Marshal(body["images"])
+	if body["pageTitle"] != page.PageTitle || body["pageStory"] != page.PageStory || body["campaignGuid"] != "guid" || body["targetAmount"] != "100" {
+		t.Errorf("expected the page fields unchanged, got %v", body)
+	}
+	if string(images) != `[{"caption":"\"caption\"","isDefault":true,"url":"https://example.com/1.jpg"}]` {
+		t.Errorf("unexpected images %s", images)
+	}
+}
@@ -0,0 +1,352 @@
+package justin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/homemade/justin/api"
+)
+
+// TenantConfigSource provides an interface for looking up the APIKeyContext used to create the Service for a tenant
+type TenantConfigSource interface {
+	APIKeyContext(tenant string) (APIKeyContext, error)
+}
+
+// TenantConfigSourceFunc provides a type for single function implementations of the TenantConfigSource interface
+type TenantConfigSourceFunc func(tenant string) (APIKeyContext, error)
+
+// APIKeyContext defines the single method TenantConfigSource interface
+func (f TenantConfigSourceFunc) APIKeyContext(tenant string) (APIKeyContext, error) {
+	return f(tenant)
+}
+
+// RegistryOptions contains optional settings for creating a Registry.
+//
+// Transport is the http.RoundTripper shared by every Service in the Registry, if not provided a clone of http.DefaultTransport is used.
+//
+// RateLimit is the maximum number of requests per second sent with each API key, if not provided requests are not rate limited.
+//
+// RateBurst is the number of requests allowed to exceed the RateLimit in a burst, if not provided a burst of 1 is used.
+//
+// IdleTimeout is the duration a Service can go unused before it is removed from the Registry, if not provided services are kept until Remove is called.
+type RegistryOptions struct {
+	Transport   http.RoundTripper
+	RateLimit   float64
+	RateBurst   int
+	IdleTimeout time.Duration
+}
+
+// Registry lazily creates and caches a Service per tenant, for operating on behalf of many charities each with their own API key.
+//
+// Every Service shares the same connection pool, requests are rate limited per API key and each api.Call is tagged with the tenant.
+// Tenants whose Service cannot be created are not kept, and services unused for the IdleTimeout are removed, so tenants whose config
+// disappears do not accumulate.
+type Registry struct {
+	source TenantConfigSource
+	opts   RegistryOptions
+
+	mu        sync.Mutex
+	tenants   map[string]*registryEntry
+	lastSweep time.Time
+
+	limitersMu sync.Mutex
+	limiters   map[string]*limiterEntry
+}
+
+type registryEntry struct {
+	mu       sync.Mutex
+	svc      *Service
+	lastUsed time.Time
+	// removed is set once the entry is no longer in the Registry, callers holding it must get a new entry
+	removed bool
+}
+
+// limiterEntry is the rate limiter for an API key, shared by users services
+type limiterEntry struct {
+	limiter *rateLimiter
+	users   int
+}
+
+// NewRegistry instantiates a Registry which creates services using the APIKeyContext provided by source
+func NewRegistry(source TenantConfigSource, opts RegistryOptions) *Registry {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	return &Registry{
+		source:   source,
+		opts:     opts,
+		tenants:  make(map[string]*registryEntry),
+		limiters: make(map[string]*limiterEntry),
+	}
+}
+
+// Service returns the Service for the specified tenant, creating and validating it on first use
+func (r *Registry) Service(tenant string) (*Service, error) {
+	r.evictIdle()
+	for {
+		entry := r.entry(tenant)
+		entry.mu.Lock()
+		if entry.removed {
+			entry.mu.Unlock()
+			continue
+		}
+		if entry.svc == nil {
+			svc, err := r.create(tenant)
+			if err != nil {
+				r.discard(tenant, entry)
+				entry.mu.Unlock()
+				return nil, err
+			}
+			entry.svc = svc
+		}
+		entry.lastUsed = time.Now()
+		svc := entry.svc
+		entry.mu.Unlock()
+		return svc, nil
+	}
+}
+
+// Rotate reloads the APIKeyContext for the specified tenant and replaces its Service, e.g. after rotating the tenant's API key.
+//
+// Calls already in progress on the previous Service are not interrupted. If the new Service cannot be created the previous one is kept.
+func (r *Registry) Rotate(tenant string) (*Service, error) {
+	r.evictIdle()
+	for {
+		entry := r.entry(tenant)
+		entry.mu.Lock()
+		if entry.removed {
+			entry.mu.Unlock()
+			continue
+		}
+		svc, err := r.create(tenant)
+		if err != nil {
+			if entry.svc == nil {
+				r.discard(tenant, entry)
+			}
+			entry.mu.Unlock()
+			return nil, err
+		}
+		if entry.svc != nil {
+			r.close(entry.svc)
+		}
+		entry.svc = svc
+		entry.lastUsed = time.Now()
+		entry.mu.Unlock()
+		return svc, nil
+	}
+}
+
+// Remove discards the Service for the specified tenant, it will be created again on next use
+func (r *Registry) Remove(tenant string) {
+	r.mu.Lock()
+	entry, exists := r.tenants[tenant]
+	delete(r.tenants, tenant)
+	r.mu.Unlock()
+	if exists {
+		entry.mu.Lock()
+		entry.removed = true
+		if entry.svc != nil {
+			r.close(entry.svc)
+			entry.svc = nil
+		}
+		entry.mu.Unlock()
+	}
+}
+
+// Tenants returns the tenants which currently have a Service in the Registry
+func (r *Registry) Tenants() []string {
+	r.evictIdle()
+	var results []string
+	for tenant, entry := range r.entries() {
+		entry.mu.Lock()
+		if entry.svc != nil {
+			results = append(results, tenant)
+		}
+		entry.mu.Unlock()
+	}
+	sort.Strings(results)
+	return results
+}
+
+func (r *Registry) entry(tenant string) *registryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, exists := r.tenants[tenant]
+	if !exists {
+		entry = &registryEntry{}
+		r.tenants[tenant] = entry
+	}
+	return entry
+}
+
+// entries returns a copy of the tenant entries, which can then be locked without holding the Registry lock
+func (r *Registry) entries() map[string]*registryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make(map[string]*registryEntry, len(r.tenants))
+	for tenant, entry := range r.tenants {
+		entries[tenant] = entry
+	}
+	return entries
+}
+
+// discard removes the entry for the tenant from the Registry, the entry must be locked by the caller
+func (r *Registry) discard(tenant string, entry *registryEntry) {
+	r.mu.Lock()
+	if r.tenants[tenant] == entry {
+		delete(r.tenants, tenant)
+	}
+	r.mu.Unlock()
+	entry.removed = true
+}
+
+// evictIdle removes the services unused for the IdleTimeout, checking at most once every IdleTimeout
+func (r *Registry) evictIdle() {
+	if r.opts.IdleTimeout <= 0 {
+		return
+	}
+	r.mu.Lock()
+	now := time.Now()
+	due := now.Sub(r.lastSweep) >= r.opts.IdleTimeout
+	if due {
+		r.lastSweep = now
+	}
+	r.mu.Unlock()
+	if !due {
+		return
+	}
+	for tenant, entry := range r.entries() {
+		if !entry.mu.TryLock() {
+			// the entry is in use so is not idle
+			continue
+		}
+		if !entry.removed && entry.svc != nil && now.Sub(entry.lastUsed) >= r.opts.IdleTimeout {
+			r.close(entry.svc)
+			entry.svc = nil
+			r.discard(tenant, entry)
+		}
+		entry.mu.Unlock()
+	}
+}
+
+func (r *Registry) create(tenant string) (*Service, error) {
+	if tenant == "" {
+		return nil, errors.New("missing tenant")
+	}
+	ctx, err := r.source.APIKeyContext(tenant)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config for tenant %s %v", tenant, err)
+	}
+	if ctx.APIKey == "" {
+		return nil, fmt.Errorf("missing api key for tenant %s", tenant)
+	}
+	ctx.HTTPLogger = api.TenantLogger(tenant, ctx.HTTPLogger)
+	client := &http.Client{
+		Timeout:   ctx.Timeout,
+		Transport: r.transport(ctx.APIKey),
+	}
+	svc, err := createWithAPIKey(ctx, client)
+	if err != nil {
+		r.release(ctx.APIKey)
+		return nil, fmt.Errorf("error creating service for tenant %s %v", tenant, err)
+	}
+	svc.tenant = tenant
+	return svc, nil
+}
+
+// close stops a Service which is no longer in the Registry and releases its rate limiter, calls in progress are not interrupted
+func (r *Registry) close(svc *Service) {
+	svc.Close()
+	r.release(svc.APIKey)
+}
+
+// transport returns the shared Transport, rate limited for the specified API key
+//
+// Each call must be matched by a call to release once the Service using the Transport is discarded.
+func (r *Registry) transport(apiKey string) http.RoundTripper {
+	if r.opts.RateLimit <= 0 {
+		return r.opts.Transport
+	}
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	l, exists := r.limiters[apiKey]
+	if !exists {
+		l = &limiterEntry{limiter: newRateLimiter(r.opts.RateLimit, r.opts.RateBurst)}
+		r.limiters[apiKey] = l
+	}
+	l.users++
+	return &rateLimitedTransport{base: r.opts.Transport, limiter: l.limiter}
+}
+
+// release removes the rate limiter for the API key once no services use it
+func (r *Registry) release(apiKey string) {
+	if r.opts.RateLimit <= 0 {
+		return
+	}
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	if l, exists := r.limiters[apiKey]; exists {
+		l.users--
+		if l.users <= 0 {
+			delete(r.limiters, apiKey)
+		}
+	}
+}
+
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// rateLimiter is a token bucket allowing rate requests per second, with bursts of up to burst requests
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *rateLimiter) wait(req *http.Request) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return req.Context().Err()
+		}
+	}
+}
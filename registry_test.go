@@ -0,0 +1,234 @@
+package justin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/homemade/justin/api"
+)
+
+// redirectTransport sends every request to the test server, whatever the JustGiving environment
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func testEmail(t *testing.T) mail.Address {
+	eml, err := mail.ParseAddress("rob@golang.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *eml
+}
+
+func TestRegistry(t *testing.T) {
+	// Fake JustGiving account availability check, only accepting known api keys
+	var mu sync.Mutex
+	validKeys := map[string]bool{"key1": true, "key2": true}
+	justgiving := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.Split(r.URL.Path, "/")[1]
+		if !validKeys[key] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Justgiving-Operation", "AccountApi:AccountAvailabilityCheck")
+	}))
+	defer justgiving.Close()
+	target, err := url.Parse(justgiving.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logged []api.Call
+	var logger api.LoggerFunc
+	logger = func(c api.Call) {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, c)
+	}
+	keys := map[string]string{"charity1": "key1", "charity2": "key2", "charity3": "invalidkey"}
+	loads := 0
+	var source TenantConfigSourceFunc
+	source = func(tenant string) (APIKeyContext, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		key, exists := keys[tenant]
+		if !exists {
+			return APIKeyContext{}, errors.New("unknown tenant")
+		}
+		return APIKeyContext{APIKey: key, Env: Sandbox, Timeout: time.Duration(5) * time.Second, HTTPLogger: logger}, nil
+	}
+	reg := NewRegistry(source, RegistryOptions{Transport: redirectTransport{target}, RateLimit: 1000})
+
+	// Services are created and validated on first use, then cached
+	svc1, err := reg.Service("charity1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := reg.Service("charity1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc1 != again || loads != 1 {
+		t.Errorf("expected Service to be cached but config was loaded %d times", loads)
+	}
+	if svc1.Tenant() != "charity1" || svc1.APIKey != "key1" {
+		t.Errorf("service is not as expected, see %#v", svc1)
+	}
+	if len(logged) != 1 || logged[0].TenantID != "charity1" {
+		t.Errorf("expected api calls to be tagged with the tenant, see %#v", logged)
+	}
+	// Invalid keys and unknown tenants fail
+	if _, err = reg.Service("charity3"); err == nil {
+		t.Error("expected Service to return error for a tenant with an invalid api key")
+	}
+	if _, err = reg.Service("charity4"); err == nil {
+		t.Error("expected Service to return error for an unknown tenant")
+	}
+	if _, err = reg.Service("charity2"); err != nil {
+		t.Fatal(err)
+	}
+	if tenants := reg.Tenants(); len(tenants) != 2 || tenants[0] != "charity1" || tenants[1] != "charity2" {
+		t.Errorf("expected Tenants to return charity1 and charity2 but returned %v", tenants)
+	}
+
+	// Rotating a key keeps the previous service until the new one is validated
+	mu.Lock()
+	keys["charity1"] = "invalidkey"
+	mu.Unlock()
+	if _, err = reg.Rotate("charity1"); err == nil {
+		t.Error("expected Rotate to return error for an invalid api key")
+	}
+	current, err := reg.Service("charity1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != svc1 {
+		t.Error("expected failed Rotate to keep the previous service")
+	}
+	mu.Lock()
+	keys["charity1"] = "key2"
+	mu.Unlock()
+	rotated, err := reg.Rotate("charity1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == svc1 || rotated.APIKey != "key2" {
+		t.Errorf("expected Rotate to replace the service, see %#v", rotated)
+	}
+	// the previous service is still usable by in-flight callers
+	if _, err = svc1.AccountAvailabilityCheck(testEmail(t)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRegistryEviction(t *testing.T) {
+	justgiving := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Split(r.URL.Path, "/")[1] != "key1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Justgiving-Operation", "AccountApi:AccountAvailabilityCheck")
+	}))
+	defer justgiving.Close()
+	target, err := url.Parse(justgiving.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	keys := map[string]string{"charity1": "key1", "charity2": "key1", "charity3": "invalidkey"}
+	var source TenantConfigSourceFunc
+	source = func(tenant string) (APIKeyContext, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		key, exists := keys[tenant]
+		if !exists {
+			return APIKeyContext{}, errors.New("unknown tenant")
+		}
+		return APIKeyContext{APIKey: key, Env: Sandbox, Timeout: time.Duration(5) * time.Second}, nil
+	}
+	idle := 100 * time.Millisecond
+	reg := NewRegistry(source, RegistryOptions{Transport: redirectTransport{target}, RateLimit: 1000, IdleTimeout: idle})
+	limiters := func() int {
+		reg.limitersMu.Lock()
+		defer reg.limitersMu.Unlock()
+		return len(reg.limiters)
+	}
+	entries := func() int {
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+		return len(reg.tenants)
+	}
+
+	// tenants which fail are not kept
+	if _, err = reg.Service("charity3"); err == nil {
+		t.Error("expected Service to return error for a tenant with an invalid api key")
+	}
+	if _, err = reg.Service("charity4"); err == nil {
+		t.Error("expected Service to return error for an unknown tenant")
+	}
+	if n, l := entries(), limiters(); n != 0 || l != 0 {
+		t.Errorf("expected failed tenants to be removed, have %d tenants and %d rate limiters", n, l)
+	}
+
+	// tenants sharing an api key share a rate limiter, which is removed with the last of them
+	if _, err = reg.Service("charity1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reg.Service("charity2"); err != nil {
+		t.Fatal(err)
+	}
+	if l := limiters(); l != 1 {
+		t.Errorf("expected 1 rate limiter, have %d", l)
+	}
+	reg.Remove("charity1")
+	if l := limiters(); l != 1 {
+		t.Errorf("expected the rate limiter to be kept for charity2, have %d", l)
+	}
+
+	// idle services are removed, including those whose config has disappeared
+	mu.Lock()
+	delete(keys, "charity2")
+	mu.Unlock()
+	time.Sleep(idle + 10*time.Millisecond)
+	if tenants := reg.Tenants(); len(tenants) != 0 {
+		t.Errorf("expected idle tenants to be removed but have %v", tenants)
+	}
+	if n, l := entries(), limiters(); n != 0 || l != 0 {
+		t.Errorf("expected idle tenants to be removed, have %d tenants and %d rate limiters", n, l)
+	}
+	if _, err = reg.Service("charity2"); err == nil {
+		t.Error("expected Service to return error for a tenant whose config has disappeared")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100, 2)
+	req := httptest.NewRequest("GET", "/", nil)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 requests in the burst, then 2 more at 100 per second
+	if taken := time.Since(start); taken < 15*time.Millisecond {
+		t.Errorf("expected rate limiting to delay requests but took %v", taken)
+	}
+}
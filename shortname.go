@@ -0,0 +1,144 @@
+package justin
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/homemade/justin/models"
+)
+
+// ErrNoShortNameAvailable is returned by ReserveableShortName when none of the candidate short names are available
+var ErrNoShortNameAvailable = errors.New("no fundraising page short name available")
+
+// ShortNameOptions contains optional settings for ReserveableShortName.
+//
+// EventName is optional, when provided candidates are also generated from the name of the event.
+//
+// Year is optional, candidates are generated with the current year if not provided.
+//
+// Alternates is the number of available alternate short names to return, if not provided 3 are returned.
+//
+// Concurrency is the maximum number of short names checked at once, if not provided 4 are checked at once.
+type ShortNameOptions struct {
+	EventName   string
+	Year        int
+	Alternates  int
+	Concurrency int
+}
+
+// ReserveableShortName returns an available fundraising page short name based on the preferred name, along with available alternates
+//
+// The preferred name is normalised (see models.NormalisePageShortName) and candidates generated from it, the event name and the year are
+// checked concurrently, followed by the JustGiving suggestions if not enough are available. Short names are not actually reserved,
+// so registration should still be prepared for the short name to be taken.
+func (svc *Service) ReserveableShortName(preferred string, opts ShortNameOptions) (shortName string, alternates []string, err error) {
+
+	svc, finish := svc.span("ReserveableShortName")
+	defer func() { finish(err) }()
+
+	name := models.NormalisePageShortName(preferred)
+	if name == "" {
+		name = models.NormalisePageShortName(opts.EventName)
+	}
+	if name == "" {
+		return "", nil, errors.New("invalid preferred short name, no valid characters")
+	}
+	if opts.Alternates <= 0 {
+		opts.Alternates = 3
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	year := opts.Year
+	if year <= 0 {
+		year = time.Now().Year()
+	}
+
+	event := models.NormalisePageShortName(opts.EventName)
+	candidates := []string{name}
+	if event != "" {
+		candidates = append(candidates, models.PageShortNameWithSuffix(name, event))
+	}
+	candidates = append(candidates, models.PageShortNameWithSuffix(name, strconv.Itoa(year)))
+	if event != "" {
+		candidates = append(candidates, models.PageShortNameWithSuffix(name, event+"-"+strconv.Itoa(year)))
+	}
+	for i := 2; i <= 5; i++ {
+		candidates = append(candidates, models.PageShortNameWithSuffix(name, strconv.Itoa(i)))
+	}
+
+	checked := make(map[string]bool)
+	available, firstErr := svc.availableShortNames(unchecked(candidates, checked), opts.Concurrency)
+	if len(available) <= opts.Alternates {
+		// fall back to the JustGiving suggestions, which are checked again as they may have been taken since
+		suggestions, err := svc.fundraisingPageShortNameSuggestions(name)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for i, s := range suggestions {
+			suggestions[i] = models.NormalisePageShortName(s)
+		}
+		more, err := svc.availableShortNames(unchecked(suggestions, checked), opts.Concurrency)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		available = append(available, more...)
+	}
+
+	if len(available) == 0 {
+		if firstErr != nil {
+			return "", nil, firstErr
+		}
+		return "", nil, ErrNoShortNameAvailable
+	}
+	if len(available) > opts.Alternates+1 {
+		available = available[:opts.Alternates+1]
+	}
+	return available[0], available[1:], nil
+}
+
+// unchecked returns the valid names which are not already checked, marking them as checked
+func unchecked(names []string, checked map[string]bool) []string {
+	var results []string
+	for _, n := range names {
+		if n != "" && !checked[n] {
+			checked[n] = true
+			results = append(results, n)
+		}
+	}
+	return results
+}
+
+// availableShortNames checks the short names concurrently, returning those which are available in their original order along with the first error
+func (svc *Service) availableShortNames(names []string, concurrency int) ([]string, error) {
+	avail := make([]bool, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, n := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, n string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			avail[i], errs[i] = svc.fundraisingPageShortNameAvailable(n)
+		}(i, n)
+	}
+	wg.Wait()
+
+	var results []string
+	var firstErr error
+	for i, n := range names {
+		if avail[i] {
+			results = append(results, n)
+		}
+		if errs[i] != nil && firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+	return results, firstErr
+}
@@ -0,0 +1,65 @@
+package justin
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReserveableShortName(t *testing.T) {
+	taken := map[string]bool{
+		"zoe-smith":                 true,
+		"zoe-smith-london-marathon": true,
+		"zoe-smith-2024":            true,
+		"zoe-smith-2":               true,
+		"zoe-smith-3":               true,
+		"zoe-smith-4":               true,
+		"zoe-smith-5":               true,
+		"zoesmith99":                true,
+	}
+	var mu sync.Mutex
+	var checked []string
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/suggest") {
+			w.Write([]byte(`{"Names": ["zoesmith99", "ZoeSmith100", "zoe-smith-2"]}`))
+			return
+		}
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		mu.Lock()
+		checked = append(checked, name)
+		mu.Unlock()
+		w.Header().Set("X-Justgiving-Operation", "FundraisingApi:FundraisingPageUrlCheck")
+		if taken[name] {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	shortName, alternates, err := svc.ReserveableShortName("Zoë Smith", ShortNameOptions{EventName: "London Marathon", Year: 2024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shortName != "zoe-smith-london-marathon-2024" {
+		t.Errorf("expected zoe-smith-london-marathon-2024, got %s", shortName)
+	}
+	if !reflect.DeepEqual(alternates, []string{"zoesmith100"}) {
+		t.Errorf("expected the available suggestion as an alternate, got %v", alternates)
+	}
+	// each name is only checked once, including the suggestions
+	if len(checked) != 10 {
+		t.Errorf("expected 10 names to be checked, got %v", checked)
+	}
+
+	taken = map[string]bool{}
+	shortName, alternates, err = svc.ReserveableShortName("Zoë Smith", ShortNameOptions{Alternates: 1})
+	if err != nil || shortName != "zoe-smith" || len(alternates) != 1 {
+		t.Errorf("expected zoe-smith with 1 alternate, got %s %v %v", shortName, alternates, err)
+	}
+
+	if _, _, err = svc.ReserveableShortName("!!!", ShortNameOptions{}); err == nil {
+		t.Error("expected a name without valid characters to be rejected")
+	}
+}
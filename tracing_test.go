@@ -0,0 +1,178 @@
+package justin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/homemade/justin/api"
+)
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*api.Span
+}
+
+func (r *recordingTracer) SpanStarted(span *api.Span) {}
+
+func (r *recordingTracer) SpanEnded(span *api.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func TestTracing(t *testing.T) {
+	var mu sync.Mutex
+	var traceparents []string
+	tracer := &recordingTracer{}
+	var origins []string
+	var logger api.LoggerFunc
+	logger = func(c api.Call) {
+		origins = append(origins, c.OriginID)
+	}
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		mu.Unlock()
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte(`{"totalPages":2,"totalFundraisingPages":2,"fundraisingPages":[{"charityId":1,"pageId":1,"pageShortName":"page1"}]}`))
+			return
+		}
+		w.Write([]byte(`{"totalPages":2,"totalFundraisingPages":2,"fundraisingPages":[{"charityId":1,"pageId":2,"pageShortName":"page2"}]}`))
+	}), func(ctx *APIKeyContext) {
+		ctx.Tracer = tracer
+		ctx.HTTPLogger = logger
+	})
+	svc.TraceOrigin("service origin")
+
+	// Continue a trace from an incoming request
+	ctx, err := api.WithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = api.WithOrigin(ctx, "request origin")
+	pages, err := svc.WithContext(ctx).FundraisingPagesForEvent(123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages but returned %d", len(pages))
+	}
+
+	// 2 request spans, 2 page spans and the FundraisingPagesForEvent span
+	if len(tracer.spans) != 5 {
+		t.Fatalf("expected 5 spans but recorded %d", len(tracer.spans))
+	}
+	spans := make(map[api.SpanID]*api.Span)
+	for _, s := range tracer.spans {
+		spans[s.SpanID] = s
+		if s.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("expected span %s to continue the incoming trace but has trace id %s", s.Name, s.TraceID)
+		}
+		if s.OriginID != "request origin" {
+			t.Errorf("expected span %s to have the request origin but has %s", s.Name, s.OriginID)
+		}
+	}
+	root := tracer.spans[4]
+	if root.Name != "FundraisingPagesForEvent" || root.ParentID.String() != "00f067aa0ba902b7" {
+		t.Errorf("expected the last span to be the FundraisingPagesForEvent child of the incoming trace, see %#v", root)
+	}
+	for i, tp := range traceparents {
+		parts := strings.Split(tp, "-")
+		if len(parts) != 4 {
+			t.Fatalf("invalid traceparent header %q", tp)
+		}
+		var reqSpan *api.Span
+		for _, s := range tracer.spans {
+			if s.SpanID.String() == parts[2] {
+				reqSpan = s
+			}
+		}
+		if reqSpan == nil || reqSpan.Name != "FundraisingPagesForEvent" || reqSpan.Attributes()["http.status_code"] != "200" {
+			t.Fatalf("expected traceparent %q to identify a request span", tp)
+		}
+		pageSpan := spans[reqSpan.ParentID]
+		if pageSpan == nil || pageSpan.ParentID != root.SpanID || !strings.HasSuffix(pageSpan.Name, " page "+string('1'+rune(i))) {
+			t.Errorf("expected request span to be the child of a page span of the FundraisingPagesForEvent span, see %#v", pageSpan)
+		}
+	}
+	for _, o := range origins {
+		if o != "request origin" {
+			t.Errorf("expected calls to be logged with the request origin but was %s", o)
+		}
+	}
+
+	// Without a tracer or trace no traceparent is sent
+	svc.Tracer = nil
+	traceparents = nil
+	if _, err = svc.FundraisingPagesForEvent(123); err != nil {
+		t.Fatal(err)
+	}
+	for _, tp := range traceparents {
+		if tp != "" {
+			t.Errorf("expected no traceparent header but sent %s", tp)
+		}
+	}
+}
+
+func TestTraceOriginWhileRequestsRun(t *testing.T) {
+	justgiving := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Justgiving-Operation", "AccountApi:AccountAvailabilityCheck")
+	}))
+	defer justgiving.Close()
+
+	var mu sync.Mutex
+	origins := make(map[string]bool)
+	var logger api.LoggerFunc
+	logger = func(c api.Call) {
+		mu.Lock()
+		defer mu.Unlock()
+		origins[c.OriginID] = true
+	}
+	// revalidation starts in the background as the service is created, before newTestService could set the BasePath,
+	// so the requests are redirected to the test server instead
+	target, err := url.Parse(justgiving.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := createWithAPIKey(APIKeyContext{
+		APIKey: "testkey", Env: Sandbox, SkipValidation: true, RevalidationInterval: time.Millisecond, HTTPLogger: logger,
+	}, &http.Client{Transport: redirectTransport{target}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Close()
+
+	// requests run on other goroutines, including the background revalidation, while the origin changes
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := svc.WithContext(context.Background()).AccountAvailabilityCheck(testEmail(t)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		svc.TraceOrigin("origin " + string('0'+rune(i)))
+	}
+	wg.Wait()
+
+	svc.TraceOrigin("final origin")
+	if _, err = svc.AccountAvailabilityCheck(testEmail(t)); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !origins["final origin"] {
+		t.Errorf("expected calls to be logged with the final origin, see %v", origins)
+	}
+}